@@ -0,0 +1,48 @@
+// Package apperrors defines the kinds of failure the service reports, so
+// callers can tell them apart without knowing which store produced them.
+package apperrors
+
+import "errors"
+
+// The kinds of failure. Match them with errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	// ErrUnavailable means the failure is likely temporary, e.g. the
+	// database could not be reached, and the request may be retried.
+	ErrUnavailable = errors.New("temporarily unavailable")
+)
+
+// Error is a failure of one kind. Message describes it to the caller; Err,
+// if set, is the underlying cause and stays reachable through errors.Is and
+// errors.As.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+// New returns an error of the given kind.
+func New(kind error, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap classifies err as the given kind, keeping its message.
+func Wrap(kind, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
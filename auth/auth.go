@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"golang-assessment/config"
+	"golang-assessment/problem"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnonymousSubject is the subject of every caller while auth is disabled.
+const AnonymousSubject = "anonymous"
+
+type roleKey struct{}
+
+type subjectKey struct{}
+
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext returns the caller's role, or "" for an unauthenticated
+// request.
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey{}).(string)
+	return role
+}
+
+// WithPrincipal puts both the subject and the role of a caller in ctx.
+func WithPrincipal(ctx context.Context, principal config.Principal) context.Context {
+	return context.WithValue(WithRole(ctx, principal.Role), subjectKey{}, principal.Subject)
+}
+
+// SubjectFromContext returns who the caller is, or "" when there is no
+// caller, as in a background job.
+func SubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey{}).(string)
+	return subject
+}
+
+// Middleware checks the bearer token against cfg.Tokens and puts the
+// principal it identifies in the request context. With auth disabled every
+// caller is AnonymousSubject with cfg.DefaultRole.
+func Middleware(cfg config.AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := config.Principal{Subject: AnonymousSubject, Role: cfg.DefaultRole}
+		if cfg.Enabled {
+			var ok bool
+			principal, ok = lookup(cfg.Tokens, bearerToken(c.GetHeader("Authorization")))
+			if !ok {
+				c.Header("WWW-Authenticate", "Bearer")
+				problem.Fail(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "missing or invalid bearer token"))
+				return
+			}
+		}
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// lookup compares against every token in constant time, so response timing
+// does not reveal how much of a guess was right.
+func lookup(tokens map[string]config.Principal, token string) (config.Principal, bool) {
+	if token == "" {
+		return config.Principal{}, false
+	}
+	var principal config.Principal
+	found := false
+	for candidate, candidatePrincipal := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			principal, found = candidatePrincipal, true
+		}
+	}
+	return principal, found
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-assessment/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestRouter(cfg config.AuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(cfg))
+	router.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, SubjectFromContext(c.Request.Context())+":"+RoleFromContext(c.Request.Context()))
+	})
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("disabled gives the default role", func(t *testing.T) {
+		router := setupTestRouter(config.AuthConfig{DefaultRole: "admin"})
+		req, _ := http.NewRequest(http.MethodGet, "/whoami", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "anonymous:admin", rr.Body.String())
+	})
+
+	router := setupTestRouter(config.AuthConfig{
+		Enabled:     true,
+		Tokens:      map[string]config.Principal{"hr-token": {Subject: "hannah", Role: "hr"}, "viewer-token": {Subject: "victor", Role: "viewer"}},
+		DefaultRole: "admin",
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/whoami", nil)
+		req.Header.Set("Authorization", "Bearer viewer-token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "victor:viewer", rr.Body.String())
+	})
+
+	for name, header := range map[string]string{
+		"missing token": "",
+		"unknown token": "Bearer nope",
+		"wrong scheme":  "Basic hr-token",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/whoami", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+		})
+	}
+}
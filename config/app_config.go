@@ -0,0 +1,342 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AppConfig is the full application configuration. Every leaf field can be
+// set from the YAML file, an EMPLOYEE_* environment variable or a command
+// line flag, in increasing order of precedence (see Load).
+type AppConfig struct {
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Pagination  PaginationConfig  `yaml:"pagination"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Redaction   RedactionConfig   `yaml:"redaction"`
+	Concurrency ConcurrencyConfig `yaml:"concurrency"`
+	Validation  ValidationConfig  `yaml:"validation"`
+	SoftDelete  SoftDeleteConfig  `yaml:"soft_delete"`
+	Audit       AuditConfig       `yaml:"audit"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Host              string        `yaml:"host"`
+	Port              int           `yaml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	// DrainDelay is how long the server keeps serving with a failing
+	// readiness probe after SIGTERM, so load balancers stop routing to it.
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// after SIGTERM before they are cut off.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// HealthCheckTimeout bounds each dependency check behind /readyz and /health.
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout"`
+}
+
+// Addr is the listen address for net/http.
+func (c ServerConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+const (
+	LogFormatText   = "text"
+	LogFormatJSON   = "json"
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+)
+
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"` // "text" or "json"
+	// Output is "stdout", "stderr" or a file path. Files are rotated by size.
+	Output     string `yaml:"output"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"`
+	MaxAgeDays int    `yaml:"max_age_days"`
+	Compress   bool   `yaml:"compress"`
+}
+
+type PaginationConfig struct {
+	DefaultLimit int `yaml:"default_limit"`
+	MaxLimit     int `yaml:"max_limit"`
+	// ClampLimit lowers a limit above MaxLimit to it instead of answering 400.
+	ClampLimit bool `yaml:"clamp_limit"`
+	// CursorSecret encrypts list cursors and must be the same on every replica.
+	// When empty a random key is used and cursors break on restart.
+	CursorSecret string `yaml:"cursor_secret" secret:"true"`
+}
+
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Tokens maps each bearer token to the principal it identifies.
+	Tokens map[string]Principal `yaml:"tokens" secret:"true"`
+	// DefaultRole is given to every caller while auth is disabled.
+	DefaultRole string `yaml:"default_role"`
+}
+
+// Principal is who a bearer token belongs to: Subject names the caller in
+// the records of who changed what, Role decides what the caller may do.
+type Principal struct {
+	Subject string `yaml:"subject"`
+	Role    string `yaml:"role"`
+}
+
+// UnmarshalText reads the "subject:role" form used in YAML and environment
+// variables.
+func (p *Principal) UnmarshalText(text []byte) error {
+	subject, role, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("%q is not a subject:role pair", text)
+	}
+	*p = Principal{Subject: strings.TrimSpace(subject), Role: strings.TrimSpace(role)}
+	return nil
+}
+
+type RedactionConfig struct {
+	// SalaryRoles are the caller roles that see salaries in API responses.
+	SalaryRoles []string `yaml:"salary_roles"`
+}
+
+type ConcurrencyConfig struct {
+	// RequireIfMatch rejects PUT, PATCH, DELETE and restores without an
+	// If-Match header with 428, so no client can overwrite changes it has not
+	// seen.
+	RequireIfMatch bool `yaml:"require_if_match"`
+}
+
+type ValidationConfig struct {
+	// MaxSalary is the exclusive upper bound on employee salaries.
+	MaxSalary float64 `yaml:"max_salary"`
+	// Positions is the catalog of allowed positions. Empty allows any.
+	Positions []string `yaml:"positions"`
+}
+
+type SoftDeleteConfig struct {
+	// AdminRoles are the caller roles that may read deleted employees with
+	// ?include_deleted=true and restore them.
+	AdminRoles []string `yaml:"admin_roles"`
+	// Retention is how long a deleted employee can be restored before the
+	// purge job removes it for good. Zero keeps deleted employees forever.
+	Retention time.Duration `yaml:"retention"`
+	// PurgeInterval is how often the purge job runs.
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+type AuditConfig struct {
+	// ReaderRoles are the caller roles that may read the audit log. Salaries
+	// in it stay hidden from roles not in redaction.salary_roles.
+	ReaderRoles []string `yaml:"reader_roles"`
+}
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+type TracingConfig struct {
+	// Exporter is "none", "stdout" (useful offline) or "otlp" (OTLP/HTTP).
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"` // host:port of the OTLP/HTTP collector
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"` // for traces without a sampled parent
+}
+
+// DefaultConfig holds the values used for anything the file, environment and
+// flags leave unset.
+func DefaultConfig() AppConfig {
+	return AppConfig{
+		Server: ServerConfig{
+			Port:               8080,
+			ReadTimeout:        15 * time.Second,
+			ReadHeaderTimeout:  5 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        60 * time.Second,
+			DrainDelay:         5 * time.Second,
+			ShutdownTimeout:    20 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Dialect: DialectPostgres,
+			Host:    "localhost",
+			Port:    5432,
+			SSLMode: "disable",
+		},
+		Logging: LoggingConfig{
+			Level:      "info",
+			Format:     LogFormatText,
+			Output:     LogOutputStdout,
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			MaxAgeDays: 30,
+		},
+		Pagination: PaginationConfig{DefaultLimit: 10, MaxLimit: 100},
+		Auth:       AuthConfig{DefaultRole: "admin"},
+		Redaction:  RedactionConfig{SalaryRoles: []string{"admin", "hr"}},
+		Validation: ValidationConfig{MaxSalary: 1000000},
+		SoftDelete: SoftDeleteConfig{AdminRoles: []string{"admin"}, Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+		Audit:      AuditConfig{ReaderRoles: []string{"admin"}},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			Endpoint:    "localhost:4318",
+			ServiceName: "employee-service",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (c *AppConfig) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		addf("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.ReadHeaderTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 || c.Server.DrainDelay < 0 {
+		addf("server timeouts must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		addf("server.shutdown_timeout must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+	if c.Server.HealthCheckTimeout <= 0 {
+		addf("server.health_check_timeout must be positive, got %s", c.Server.HealthCheckTimeout)
+	}
+
+	db := c.Database
+	switch db.Dialect {
+	case DialectPostgres, DialectMySQL:
+		if db.Host == "" {
+			addf("database.host is required for the %s dialect", db.Dialect)
+		}
+		if db.Port < 1 || db.Port > 65535 {
+			addf("database.port must be between 1 and 65535, got %d", db.Port)
+		}
+		if db.Username == "" {
+			addf("database.username is required for the %s dialect", db.Dialect)
+		}
+		if db.DBName == "" {
+			addf("database.dbname is required for the %s dialect", db.Dialect)
+		}
+	case DialectSQLite:
+		if db.Path == "" {
+			addf("database.path is required for the sqlite dialect (a file or %q)", SQLiteInMemory)
+		}
+	case DialectMemory:
+	default:
+		addf("database.dialect must be one of postgres, mysql, sqlite, memory, got %q", db.Dialect)
+	}
+	if db.ConnectTimeout < 0 {
+		addf("database.connect_timeout must not be negative")
+	}
+	if db.TimeZone != "" {
+		if _, err := time.LoadLocation(db.TimeZone); err != nil {
+			addf("database.timezone %q is not a known time zone", db.TimeZone)
+		}
+	}
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		addf("logging.level %q is not a valid level", c.Logging.Level)
+	}
+	if c.Logging.Format != LogFormatText && c.Logging.Format != LogFormatJSON {
+		addf("logging.format must be text or json, got %q", c.Logging.Format)
+	}
+	if c.Logging.Output == "" {
+		addf("logging.output must be stdout, stderr or a file path")
+	}
+	if c.Logging.MaxSizeMB < 0 || c.Logging.MaxBackups < 0 || c.Logging.MaxAgeDays < 0 {
+		addf("logging rotation limits must not be negative")
+	}
+
+	if c.Pagination.DefaultLimit < 1 {
+		addf("pagination.default_limit must be at least 1, got %d", c.Pagination.DefaultLimit)
+	}
+	if c.Pagination.MaxLimit < c.Pagination.DefaultLimit {
+		addf("pagination.max_limit (%d) must not be below pagination.default_limit (%d)", c.Pagination.MaxLimit, c.Pagination.DefaultLimit)
+	}
+
+	if c.Auth.Enabled && len(c.Auth.Tokens) == 0 {
+		addf("auth.tokens must not be empty when auth.enabled is true")
+	}
+	for _, principal := range c.Auth.Tokens {
+		if principal.Subject == "" || principal.Role == "" {
+			addf("auth.tokens must give every token a subject and a role")
+			break
+		}
+	}
+
+	if c.Validation.MaxSalary <= 0 {
+		addf("validation.max_salary must be positive, got %v", c.Validation.MaxSalary)
+	}
+
+	if c.SoftDelete.Retention < 0 {
+		addf("soft_delete.retention must not be negative, got %s", c.SoftDelete.Retention)
+	}
+	if c.SoftDelete.Retention > 0 && c.SoftDelete.PurgeInterval <= 0 {
+		addf("soft_delete.purge_interval must be positive, got %s", c.SoftDelete.PurgeInterval)
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		if c.Tracing.Endpoint == "" {
+			addf("tracing.endpoint is required for the otlp exporter")
+		}
+	default:
+		addf("tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		addf("tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+const redacted = "******"
+
+// Redacted returns a copy that is safe to log: every field tagged
+// `secret:"true"` is masked.
+func (c AppConfig) Redacted() AppConfig {
+	copied := c
+	for _, s := range settings(reflect.ValueOf(&copied).Elem(), "") {
+		if !s.secret || s.value.IsZero() {
+			continue
+		}
+		switch s.value.Kind() {
+		case reflect.String:
+			s.value.SetString(redacted)
+		case reflect.Map:
+			// Keys are the secret (e.g. tokens); keep the values visible.
+			masked := reflect.MakeMap(s.value.Type())
+			for i, key := range s.value.MapKeys() {
+				masked.SetMapIndex(reflect.ValueOf(fmt.Sprintf("%s%d", redacted, i+1)), s.value.MapIndex(key))
+			}
+			s.value.Set(masked)
+		}
+	}
+	return copied
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestConfig(t *testing.T, body string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("error writing config: %v", err)
+	}
+	return path
+}
+
+const testConfigYAML = `
+server:
+  port: 9090
+database:
+  dialect: "postgres"
+  username: "postgres"
+  password: "root123"
+  host: "db"
+  port: 5432
+  dbname: "employees"
+`
+
+func TestLoad(t *testing.T) {
+	t.Run("TestDefaultsWithoutFile", func(t *testing.T) {
+		wd, _ := os.Getwd()
+		os.Chdir(t.TempDir())
+		defer os.Chdir(wd)
+		t.Setenv("EMPLOYEE_DATABASE_DIALECT", "memory")
+
+		cfg, args, err := Load(nil)
+		assert.Nil(t, err)
+		assert.Empty(t, args)
+		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, 10, cfg.Pagination.DefaultLimit)
+		assert.Equal(t, DialectMemory, cfg.Database.Dialect)
+	})
+
+	t.Run("TestFileFromFlag", func(t *testing.T) {
+		path := writeTestConfig(t, testConfigYAML)
+
+		cfg, _, err := Load([]string{"-config", path})
+		assert.Nil(t, err)
+		assert.Equal(t, 9090, cfg.Server.Port)
+		assert.Equal(t, "db", cfg.Database.Host)
+		// Unset keys keep their defaults
+		assert.Equal(t, "info", cfg.Logging.Level)
+	})
+
+	t.Run("TestFileFromEnv", func(t *testing.T) {
+		t.Setenv("EMPLOYEE_CONFIG", writeTestConfig(t, testConfigYAML))
+
+		cfg, _, err := Load(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 9090, cfg.Server.Port)
+	})
+
+	t.Run("TestMissingExplicitFile", func(t *testing.T) {
+		_, _, err := Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestUnknownKey", func(t *testing.T) {
+		path := writeTestConfig(t, testConfigYAML+"  passwrod: \"typo\"\n")
+		_, _, err := Load([]string{"-config", path})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestPrecedence", func(t *testing.T) {
+		path := writeTestConfig(t, testConfigYAML)
+		t.Setenv("EMPLOYEE_SERVER_PORT", "7070")
+		t.Setenv("EMPLOYEE_DATABASE_HOST", "env-db")
+		t.Setenv("EMPLOYEE_AUTH_TOKENS", "secret-1:alice:admin, secret-2:bob:viewer")
+
+		cfg, args, err := Load([]string{"-config", path, "-database.host", "flag-db", "migrate", "up"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"migrate", "up"}, args)
+		assert.Equal(t, 7070, cfg.Server.Port)
+		assert.Equal(t, "flag-db", cfg.Database.Host)
+		assert.Equal(t, map[string]Principal{"secret-1": {Subject: "alice", Role: "admin"}, "secret-2": {Subject: "bob", Role: "viewer"}}, cfg.Auth.Tokens)
+	})
+
+	t.Run("TestTokenPrincipals", func(t *testing.T) {
+		path := writeTestConfig(t, testConfigYAML+`
+auth:
+  tokens:
+    "secret-1": "alice:admin"
+    "secret-2": {subject: "bob", role: "viewer"}
+`)
+
+		cfg, _, err := Load([]string{"-config", path})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]Principal{"secret-1": {Subject: "alice", Role: "admin"}, "secret-2": {Subject: "bob", Role: "viewer"}}, cfg.Auth.Tokens)
+
+		// A bare role no longer says who the caller is
+		t.Setenv("EMPLOYEE_AUTH_TOKENS", "secret-1:admin")
+		_, _, err = Load([]string{"-config", path})
+		assert.ErrorContains(t, err, "not a subject:role pair")
+	})
+
+	t.Run("TestAggregatedErrors", func(t *testing.T) {
+		path := writeTestConfig(t, testConfigYAML+"logging:\n  format: xml\n")
+		t.Setenv("EMPLOYEE_SERVER_PORT", "not-a-port")
+		t.Setenv("EMPLOYEE_LOGGING_LEVEL", "loud")
+
+		// Values that do not parse and settings that fail validation are
+		// reported together
+		_, _, err := Load([]string{"-config", path, "-pagination.max_limit", "many"})
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Len(t, validationErr.Problems, 4)
+		assert.Contains(t, err.Error(), "EMPLOYEE_SERVER_PORT")
+		assert.Contains(t, err.Error(), "-pagination.max_limit")
+		assert.Contains(t, err.Error(), "logging.level")
+		assert.Contains(t, err.Error(), "logging.format")
+	})
+}
+
+func TestAppConfig_Validate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Port = 0
+	cfg.Database = DatabaseConfig{Dialect: DialectSQLite}
+	cfg.Logging.Format = "xml"
+	cfg.Pagination = PaginationConfig{DefaultLimit: 50, MaxLimit: 10}
+	cfg.Auth.Enabled = true
+	cfg.Validation.MaxSalary = 0
+	cfg.SoftDelete.PurgeInterval = 0
+	cfg.Auth.Tokens = map[string]Principal{"secret-1": {Role: "admin"}}
+
+	err := cfg.Validate()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 7)
+	assert.Contains(t, err.Error(), "auth.tokens must give every token a subject and a role")
+
+	valid := DefaultConfig()
+	valid.Database.Dialect = DialectMemory
+	assert.Nil(t, valid.Validate())
+}
+
+func TestAppConfig_Redacted(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Database.Password = "root123"
+	cfg.Auth.Tokens = map[string]Principal{"secret-1": {Subject: "alice", Role: "admin"}}
+
+	logged := fmt.Sprintf("%+v", cfg.Redacted())
+	assert.NotContains(t, logged, "root123")
+	assert.NotContains(t, logged, "secret-1")
+	assert.Contains(t, logged, "alice")
+
+	// The original is left untouched
+	assert.Equal(t, "root123", cfg.Database.Password)
+	assert.Equal(t, map[string]Principal{"secret-1": {Subject: "alice", Role: "admin"}}, cfg.Auth.Tokens)
+}
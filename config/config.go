@@ -1,63 +1,174 @@
 package config
 
 import (
-	"golang-assessment/logger"
-	"golang-assessment/models"
-	"log"
-	"os"
+	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
-	"gopkg.in/yaml.v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	mysqlDriver "gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+	// DialectMemory selects the in-memory employee store; no database is opened.
+	DialectMemory = "memory"
+)
+
+// SQLiteInMemory is the path that keeps a SQLite database in memory.
+const SQLiteInMemory = ":memory:"
+
 type DatabaseConfig struct {
 	Dialect  string `yaml:"dialect"`
 	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	Password string `yaml:"password" secret:"true"`
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"` // Change the type to int
 	DBName   string `yaml:"dbname"`
+	// Path is the SQLite database file, or ":memory:".
+	Path           string `yaml:"path"`
+	SSLMode        string `yaml:"sslmode"`
+	TimeZone       string `yaml:"timezone"`
+	ConnectTimeout int    `yaml:"connect_timeout"` // seconds, 0 uses the driver default
 }
 
-func LoadDatabaseConfig() *DatabaseConfig {
-	file, err := os.Open("config/config.yaml")
-	if err != nil {
-		log.Fatalf("Error opening config file: %v", err)
+// DSN builds the connection string for the configured dialect.
+func (c *DatabaseConfig) DSN() (string, error) {
+	switch c.Dialect {
+	case DialectPostgres, "":
+		return c.postgresDSN(), nil
+	case DialectMySQL:
+		return c.mysqlDSN()
+	case DialectSQLite:
+		return c.sqliteDSN()
+	default:
+		return "", fmt.Errorf("unsupported database dialect %q", c.Dialect)
 	}
-	defer file.Close()
+}
 
-	var config DatabaseConfig
-	decoder := yaml.NewDecoder(file)
-	err = decoder.Decode(&config)
-	if err != nil {
-		log.Fatalf("Error decoding config file: %v", err)
+func (c *DatabaseConfig) postgresDSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	params := []string{
+		"host=" + quotePostgresValue(c.Host),
+		"user=" + quotePostgresValue(c.Username),
+		"password=" + quotePostgresValue(c.Password),
+		"dbname=" + quotePostgresValue(c.DBName),
+		"port=" + strconv.Itoa(c.Port),
+		"sslmode=" + quotePostgresValue(sslMode),
+	}
+	if c.TimeZone != "" {
+		params = append(params, "TimeZone="+quotePostgresValue(c.TimeZone))
+	}
+	if c.ConnectTimeout > 0 {
+		params = append(params, "connect_timeout="+strconv.Itoa(c.ConnectTimeout))
+	}
+	return strings.Join(params, " ")
+}
+
+// quotePostgresValue quotes a keyword/value DSN value when it is empty or
+// contains characters that would otherwise end it early.
+func quotePostgresValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func (c *DatabaseConfig) mysqlDSN() (string, error) {
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = c.Username
+	mysqlConfig.Passwd = c.Password
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = c.Host + ":" + strconv.Itoa(c.Port)
+	mysqlConfig.DBName = c.DBName
+	mysqlConfig.ParseTime = true
+	mysqlConfig.Params = map[string]string{"charset": "utf8mb4"}
+	if c.TimeZone != "" {
+		location, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return "", fmt.Errorf("invalid timezone %q: %w", c.TimeZone, err)
+		}
+		mysqlConfig.Loc = location
+	}
+	if c.ConnectTimeout > 0 {
+		mysqlConfig.Timeout = time.Duration(c.ConnectTimeout) * time.Second
+	}
+	if c.SSLMode != "" && c.SSLMode != "disable" {
+		mysqlConfig.TLSConfig = c.SSLMode
 	}
+	return mysqlConfig.FormatDSN(), nil
+}
 
-	log.Printf("Loaded config: %+v", config) // Add this line for debugging
+func (c *DatabaseConfig) sqliteDSN() (string, error) {
+	if c.Path == "" {
+		return "", fmt.Errorf("sqlite dialect requires a path (a file or %q)", SQLiteInMemory)
+	}
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	if c.ConnectTimeout > 0 {
+		// SQLite has no connection step to time out; waiting on a locked
+		// database is the closest equivalent.
+		query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", c.ConnectTimeout*1000))
+	}
+	return c.Path + "?" + query.Encode(), nil
+}
 
-	return &config
+// Now is the time stores stamp rows with: UTC, to the microsecond, the finest
+// precision Postgres and MySQL keep.
+func Now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
 }
 
-func SetupDatabase() *gorm.DB {
-	dbConfig := LoadDatabaseConfig()
+// OpenDatabase connects to the configured database without migrating it.
+func OpenDatabase(dbConfig *DatabaseConfig) (*gorm.DB, error) {
+	dsn, err := dbConfig.DSN()
+	if err != nil {
+		return nil, err
+	}
+
+	var dialector gorm.Dialector
+	switch dbConfig.Dialect {
+	case DialectMySQL:
+		dialector = mysqlDriver.Open(dsn)
+	case DialectSQLite:
+		dialector = sqlite.Open(dsn)
+	default:
+		dialector = postgres.Open(dsn)
+	}
 
-	// Constructing the DSN with proper parameter order
-	dsn := "host=" + dbConfig.Host +
-		" user=" + dbConfig.Username +
-		" password=" + dbConfig.Password +
-		" dbname=" + dbConfig.DBName +
-		" port=" + strconv.Itoa(dbConfig.Port) + // Convert port to string
-		" sslmode=disable" +
-		" TimeZone=Asia/Kolkata"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError turns each driver's constraint violations into gorm's
+	// errors, e.g. gorm.ErrDuplicatedKey, so callers need not know the driver.
+	// NowFunc stamps rows at the precision every dialect stores, so a time
+	// read back equals the one that was written.
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true, NowFunc: Now})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return nil, err
 	}
 
-	db.AutoMigrate(&models.Employee{})
-	logger.Log.Info("Database connected and migrated")
+	if dbConfig.Dialect == DialectSQLite && dbConfig.Path == SQLiteInMemory {
+		// Every new connection to ":memory:" gets its own empty database, so
+		// the pool must hold on to exactly one.
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
 
-	return db
+	return db, nil
 }
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseConfig_DSN(t *testing.T) {
+	t.Run("TestPostgresDSN", func(t *testing.T) {
+		dbConfig := &DatabaseConfig{Dialect: DialectPostgres, Username: "postgres", Password: "root 123", Host: "localhost", Port: 5432, DBName: "postgres", TimeZone: "Asia/Kolkata", ConnectTimeout: 5}
+		dsn, err := dbConfig.DSN()
+		assert.Nil(t, err)
+		assert.Equal(t, "host=localhost user=postgres password='root 123' dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Kolkata connect_timeout=5", dsn)
+	})
+
+	t.Run("TestMySQLDSN", func(t *testing.T) {
+		dbConfig := &DatabaseConfig{Dialect: DialectMySQL, Username: "root", Password: "secret", Host: "db", Port: 3306, DBName: "employees", TimeZone: "UTC", ConnectTimeout: 5}
+		dsn, err := dbConfig.DSN()
+		assert.Nil(t, err)
+		assert.Equal(t, "root:secret@tcp(db:3306)/employees?parseTime=true&timeout=5s&charset=utf8mb4", dsn)
+
+		dbConfig.TimeZone = "Not/AZone"
+		_, err = dbConfig.DSN()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestSQLiteDSN", func(t *testing.T) {
+		dbConfig := &DatabaseConfig{Dialect: DialectSQLite, Path: SQLiteInMemory}
+		dsn, err := dbConfig.DSN()
+		assert.Nil(t, err)
+		assert.Equal(t, ":memory:?_pragma=foreign_keys%281%29", dsn)
+
+		dbConfig.Path = ""
+		_, err = dbConfig.DSN()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestUnknownDialect", func(t *testing.T) {
+		_, err := (&DatabaseConfig{Dialect: "oracle"}).DSN()
+		assert.NotNil(t, err)
+	})
+}
+
+func TestOpenDatabase_SQLiteInMemory(t *testing.T) {
+	db, err := OpenDatabase(&DatabaseConfig{Dialect: DialectSQLite, Path: SQLiteInMemory})
+	assert.Nil(t, err)
+	assert.Nil(t, db.Exec("CREATE TABLE probe (id integer)").Error)
+
+	// The pool is pinned to one connection, so the table is still visible
+	var count int64
+	assert.Nil(t, db.Table("probe").Count(&count).Error)
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	EnvPrefix         = "EMPLOYEE_"
+	DefaultConfigPath = "config/config.yaml"
+)
+
+// Load builds the configuration from, lowest precedence first, DefaultConfig,
+// the YAML file, EMPLOYEE_* environment variables and command line flags,
+// then validates it. The file is chosen by -config or EMPLOYEE_CONFIG; the
+// default path may be missing, an explicitly chosen one may not.
+//
+// args are the command line arguments without the program name. The
+// arguments left after the flags (e.g. a subcommand) are returned.
+func Load(args []string) (*AppConfig, []string, error) {
+	cfg := DefaultConfig()
+	fields := settings(reflect.ValueOf(&cfg).Elem(), "")
+
+	flags := flag.NewFlagSet("golang-assessment", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configPath := flags.String("config", "", "path to the YAML config file (env "+EnvPrefix+"CONFIG)")
+	overrides := map[string]string{}
+	for _, s := range fields {
+		flags.Var(&flagValue{path: s.path, overrides: overrides}, s.path, "overrides "+s.path+" (env "+s.envName()+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flags.SetOutput(os.Stderr)
+			flags.PrintDefaults()
+		}
+		return nil, nil, err
+	}
+
+	path, explicit := *configPath, true
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path, explicit = DefaultConfigPath, false
+	}
+	if err := loadFile(path, &cfg, explicit); err != nil {
+		return nil, nil, err
+	}
+
+	var problems []string
+	for _, s := range fields {
+		if raw, ok := os.LookupEnv(s.envName()); ok {
+			if err := s.set(raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", s.envName(), err))
+			}
+		}
+	}
+	for _, s := range fields {
+		if raw, ok := overrides[s.path]; ok {
+			if err := s.set(raw); err != nil {
+				problems = append(problems, fmt.Sprintf("-%s: %v", s.path, err))
+			}
+		}
+	}
+	// A setting that failed to parse keeps its earlier value, so the rest of
+	// the configuration is still validated and every problem reported at once
+	var invalid *ValidationError
+	if err := cfg.Validate(); errors.As(err, &invalid) {
+		problems = append(problems, invalid.Problems...)
+	} else if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+	return &cfg, flags.Args(), nil
+}
+
+func loadFile(path string, cfg *AppConfig, required bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("opening config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.SetStrict(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// flagValue records a flag for Load to apply after the file and environment.
+type flagValue struct {
+	path      string
+	overrides map[string]string
+}
+
+func (f *flagValue) String() string {
+	if f.overrides == nil {
+		return ""
+	}
+	return f.overrides[f.path]
+}
+
+func (f *flagValue) Set(raw string) error {
+	f.overrides[f.path] = raw
+	return nil
+}
+
+// setting is one leaf field of AppConfig, addressed by its dotted YAML path.
+type setting struct {
+	path   string
+	value  reflect.Value
+	secret bool
+}
+
+func settings(v reflect.Value, prefix string) []setting {
+	var result []setting
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if field.Type.Kind() == reflect.Struct {
+			result = append(result, settings(v.Field(i), name)...)
+			continue
+		}
+		result = append(result, setting{path: name, value: v.Field(i), secret: field.Tag.Get("secret") == "true"})
+	}
+	return result
+}
+
+func (s setting) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.path, ".", "_"))
+}
+
+// set parses raw into the field. Maps are written as "key:value,key:value"
+// and slices as "a,b,c"; map values may parse themselves with UnmarshalText.
+func (s setting) set(raw string) error {
+	if s.value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		s.value.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		s.value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		s.value.SetBool(b)
+	case reflect.Slice:
+		items := reflect.MakeSlice(s.value.Type(), 0, 0)
+		for _, item := range splitList(raw) {
+			items = reflect.Append(items, reflect.ValueOf(item))
+		}
+		s.value.Set(items)
+	case reflect.Map:
+		entries := reflect.MakeMap(s.value.Type())
+		for _, item := range splitList(raw) {
+			key, value, ok := strings.Cut(item, ":")
+			if !ok {
+				return fmt.Errorf("%q is not a key:value pair", item)
+			}
+			entry := reflect.New(s.value.Type().Elem())
+			if unmarshaler, ok := entry.Interface().(encoding.TextUnmarshaler); ok {
+				if err := unmarshaler.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+					return err
+				}
+			} else {
+				entry.Elem().SetString(strings.TrimSpace(value))
+			}
+			entries.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), entry.Elem())
+		}
+		s.value.Set(entries)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
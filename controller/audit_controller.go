@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"golang-assessment/auth"
+	"golang-assessment/config"
+	"golang-assessment/models"
+	"golang-assessment/pagination"
+	"golang-assessment/problem"
+	"golang-assessment/redact"
+	repository "golang-assessment/respository"
+	"golang-assessment/services"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditController serves the audit log to the roles in
+// config.AuditConfig.ReaderRoles.
+type AuditController struct {
+	service *services.AuditService
+	policy  *redact.Policy
+	limits  pagination.Limits
+	audit   config.AuditConfig
+	cursors *pagination.CursorCodec
+	log     *logrus.Logger
+}
+
+func NewAuditController(service *services.AuditService, policy *redact.Policy, limits pagination.Limits, audit config.AuditConfig, cursors *pagination.CursorCodec, log *logrus.Logger) *AuditController {
+	return &AuditController{service: service, policy: policy, limits: limits, audit: audit, cursors: cursors, log: log}
+}
+
+// ListAudit serves the entries of every employee.
+func (ctrl *AuditController) ListAudit(c *gin.Context) {
+	ctrl.list(c, repository.AuditQuery{})
+}
+
+// ListEmployeeAudit serves the entries of one employee. It does not check
+// that the employee exists, since the history of a purged one is kept.
+func (ctrl *AuditController) ListEmployeeAudit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Invalid ID: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid ID"))
+		return
+	}
+	ctrl.list(c, repository.AuditQuery{EmployeeID: id})
+}
+
+// list walks the entries matching query, oldest first, with the encrypted
+// cursors of the employee list. ?since= takes an RFC 3339 time.
+func (ctrl *AuditController) list(c *gin.Context, query repository.AuditQuery) {
+	role := auth.RoleFromContext(c.Request.Context())
+	if !slices.Contains(ctrl.audit.ReaderRoles, role) {
+		problem.Fail(c, problem.New(http.StatusForbidden, problem.CodeForbidden, "the audit log is only available to audit reader roles"))
+		return
+	}
+	if raw := c.Query("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			problem.Fail(c, problem.InvalidQuery("since", "must be an RFC 3339 time, such as 2024-01-02T15:04:05Z"))
+			return
+		}
+		query.Since = since
+	}
+	limit, err := ctrl.limits.Limit(c.Query("limit"))
+	if err != nil {
+		problem.Fail(c, problem.InvalidQuery("limit", err.Error()))
+		return
+	}
+	var after int64
+	if token := c.Query("cursor"); token != "" {
+		if after, err = ctrl.decodeCursor(token, query); err != nil {
+			ctrl.log.WithContext(c.Request.Context()).Warnf("Rejected cursor: %v", err)
+			problem.Fail(c, problem.InvalidQuery("cursor", pagination.ErrInvalidCursor.Error()))
+			return
+		}
+	}
+
+	page, err := ctrl.service.ListAudit(c.Request.Context(), query, after, limit)
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error listing audit entries: %v", err)
+		problem.Fail(c, err)
+		return
+	}
+	ctrl.log.WithContext(c.Request.Context()).WithField("count", len(page.Entries)).Debug("Listed audit entries")
+
+	var next string
+	if page.HasNext {
+		last := page.Entries[len(page.Entries)-1]
+		next = ctrl.cursors.Encode(pagination.Cursor{Key: []interface{}{last.ID}, Query: auditCursorQuery(query)})
+	}
+	data := newAuditEntryResponses(page.Entries, func(field string) bool {
+		return ctrl.policy.Hides(role, redact.Class(models.Employee{}, field))
+	})
+	response := newCursorListResponse(c, page.Limit, next, data)
+	c.Header("Link", response.Links.header())
+	c.JSON(http.StatusOK, response)
+}
+
+// decodeCursor returns the ID of the entry a cursor issued for query ends at.
+func (ctrl *AuditController) decodeCursor(token string, query repository.AuditQuery) (int64, error) {
+	cursor, err := ctrl.cursors.Decode(token)
+	if err != nil {
+		return 0, err
+	}
+	if cursor.Query != auditCursorQuery(query) {
+		return 0, errors.New("cursor was issued for a different audit query")
+	}
+	if len(cursor.Key) != 1 {
+		return 0, errors.New("cursor does not hold an audit entry ID")
+	}
+	// JSON numbers decode as float64
+	id, ok := cursor.Key[0].(float64)
+	if !ok || id < 0 || id != math.Trunc(id) {
+		return 0, errors.New("cursor does not hold an audit entry ID")
+	}
+	return int64(id), nil
+}
+
+// auditCursorQuery keeps audit cursors apart from employee list cursors,
+// which are encrypted with the same key.
+func auditCursorQuery(query repository.AuditQuery) string {
+	return "audit?" + query.String()
+}
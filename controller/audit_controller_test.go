@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang-assessment/auth"
+	"golang-assessment/config"
+	loggerNew "golang-assessment/logger"
+	"golang-assessment/pagination"
+	"golang-assessment/problem"
+	"golang-assessment/redact"
+	repository "golang-assessment/respository"
+	"golang-assessment/services"
+	"golang-assessment/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEndpoints(t *testing.T) {
+	// Setup
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
+	ctx := loggerNew.WithRequestID(auth.WithPrincipal(context.Background(), config.Principal{Subject: "hannah", Role: "hr"}), "req-1")
+	service.UpdateEmployee(ctx, 1, nil, services.EmployeeInput{Name: "John Doe", Position: "Developer", Salary: 65000})
+	service.DeleteEmployee(ctx, 2, nil)
+
+	controller := NewAuditController(services.NewAuditService(repo), redact.NewPolicy(config.RedactionConfig{SalaryRoles: []string{"admin"}}), pagination.NewLimits(config.DefaultConfig().Pagination), config.AuditConfig{ReaderRoles: []string{"admin", "auditor"}}, pagination.NewCursorCodec([]byte("test")), loggerNew.Discard())
+	router := gin.Default()
+	router.Use(problem.Middleware(), auth.Middleware(config.AuthConfig{
+		Enabled: true,
+		Tokens:  map[string]config.Principal{"admin-token": {Subject: "ada", Role: "admin"}, "auditor-token": {Subject: "otto", Role: "auditor"}, "viewer-token": {Subject: "victor", Role: "viewer"}},
+	}))
+	router.GET("/audit", controller.ListAudit)
+	router.GET("/employees/:id/audit", controller.ListEmployeeAudit)
+	send := func(target, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	type page struct {
+		Data       []AuditEntryResponse `json:"data"`
+		HasNext    bool                 `json:"has_next"`
+		NextCursor string               `json:"next_cursor"`
+		Links      cursorLinks          `json:"links"`
+	}
+	read := func(t *testing.T, rr *httptest.ResponseRecorder) page {
+		t.Helper()
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var p page
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &p))
+		return p
+	}
+
+	t.Run("TestAudit_EmployeeHistory", func(t *testing.T) {
+		p := read(t, send("/employees/1/audit", "admin-token"))
+		assert.Len(t, p.Data, 1)
+		entry := p.Data[0]
+		assert.Equal(t, 1, entry.EmployeeID)
+		assert.Equal(t, "update", entry.Operation)
+		assert.Equal(t, "hannah", entry.Actor)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.Equal(t, "/employees/1", entry.Links.Employee)
+		assert.Equal(t, `[{"field":"salary","before":60000,"after":65000}]`, mustJSON(t, entry.Changes))
+
+		// Deleted employees keep their history
+		p = read(t, send("/employees/2/audit", "admin-token"))
+		assert.Len(t, p.Data, 1)
+		assert.Equal(t, "delete", p.Data[0].Operation)
+	})
+
+	t.Run("TestAudit_SalaryHidden", func(t *testing.T) {
+		p := read(t, send("/employees/1/audit", "auditor-token"))
+		assert.Equal(t, `[{"field":"salary","before":"[REDACTED]","after":"[REDACTED]"}]`, mustJSON(t, p.Data[0].Changes))
+
+		p = read(t, send("/employees/2/audit", "auditor-token"))
+		assert.Equal(t, `[{"field":"name","before":"Jane Doe","after":null},{"field":"position","before":"Manager","after":null},{"field":"salary","before":"[REDACTED]","after":null}]`, mustJSON(t, p.Data[0].Changes))
+	})
+
+	t.Run("TestAudit_Forbidden", func(t *testing.T) {
+		assertProblem(t, send("/audit", "viewer-token"), http.StatusForbidden, gin.H{"code": "forbidden"})
+		assertProblem(t, send("/employees/1/audit", "viewer-token"), http.StatusForbidden, gin.H{"code": "forbidden"})
+	})
+
+	t.Run("TestAudit_Since", func(t *testing.T) {
+		assert.Len(t, read(t, send("/audit?since=2000-01-01T00:00:00Z", "admin-token")).Data, 2)
+		assert.Empty(t, read(t, send("/audit?since=2999-01-01T00:00:00%2B02:00", "admin-token")).Data)
+		assertProblem(t, send("/audit?since=yesterday", "admin-token"), http.StatusBadRequest, gin.H{"code": "invalid_query", "parameter": "since"})
+	})
+
+	t.Run("TestAudit_Pages", func(t *testing.T) {
+		first := read(t, send("/audit?limit=1", "admin-token"))
+		assert.Len(t, first.Data, 1)
+		assert.True(t, first.HasNext)
+		assert.NotEmpty(t, first.NextCursor)
+
+		second := read(t, send(first.Links.Next, "admin-token"))
+		assert.Len(t, second.Data, 1)
+		assert.Greater(t, second.Data[0].ID, first.Data[0].ID)
+		assert.False(t, second.HasNext)
+		assert.Empty(t, second.Links.Next)
+
+		// A cursor only works for the query it was issued for
+		cursor := url.QueryEscape(first.NextCursor)
+		assertProblem(t, send("/employees/1/audit?cursor="+cursor, "admin-token"), http.StatusBadRequest, gin.H{"code": "invalid_query", "parameter": "cursor"})
+		assertProblem(t, send("/audit?since=2000-01-01T00:00:00Z&cursor="+cursor, "admin-token"), http.StatusBadRequest, gin.H{"parameter": "cursor"})
+		assertProblem(t, send("/audit?cursor=garbage", "admin-token"), http.StatusBadRequest, gin.H{"parameter": "cursor"})
+
+		// A cursor the key decrypts but that holds no entry ID is rejected too
+		for _, key := range [][]interface{}{nil, {1.0, 2.0}, {"1"}} {
+			token := url.QueryEscape(controller.cursors.Encode(pagination.Cursor{Key: key, Query: auditCursorQuery(repository.AuditQuery{})}))
+			assertProblem(t, send("/audit?cursor="+token, "admin-token"), http.StatusBadRequest, gin.H{"parameter": "cursor", "detail": "cursor: invalid cursor"})
+		}
+	})
+
+	t.Run("TestAudit_InvalidID", func(t *testing.T) {
+		assertProblem(t, send("/employees/abc/audit", "admin-token"), http.StatusBadRequest, gin.H{"code": "invalid_id"})
+	})
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	body, err := json.Marshal(v)
+	assert.Nil(t, err)
+	return string(body)
+}
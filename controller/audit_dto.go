@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"golang-assessment/models"
+	"golang-assessment/redact"
+	"time"
+)
+
+// AuditEntryResponse is an audit entry as the API returns it.
+type AuditEntryResponse struct {
+	ID         int64                `json:"id"`
+	EmployeeID int                  `json:"employee_id"`
+	Operation  string               `json:"operation"`
+	Actor      string               `json:"actor"`
+	RequestID  string               `json:"request_id"`
+	Timestamp  time.Time            `json:"timestamp"`
+	Changes    []models.AuditChange `json:"changes"`
+	Links      auditLinks           `json:"links"`
+}
+
+type auditLinks struct {
+	Employee string `json:"employee"`
+}
+
+// newAuditEntryResponses maps a page of entries. The values of fields hide
+// reports true for are replaced by redact.Mask, so the caller still sees
+// that the field changed.
+func newAuditEntryResponses(entries []models.AuditEntry, hide func(field string) bool) []AuditEntryResponse {
+	responses := make([]AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		changes := make([]models.AuditChange, len(entry.Changes))
+		for j, change := range entry.Changes {
+			if hide(change.Field) {
+				change.Before, change.After = masked(change.Before), masked(change.After)
+			}
+			changes[j] = change
+		}
+		responses[i] = AuditEntryResponse{
+			ID:         entry.ID,
+			EmployeeID: entry.EmployeeID,
+			Operation:  entry.Operation,
+			Actor:      entry.Actor,
+			RequestID:  entry.RequestID,
+			Timestamp:  entry.Timestamp,
+			Changes:    changes,
+			Links:      auditLinks{Employee: employeeURL(entry.EmployeeID)},
+		}
+	}
+	return responses
+}
+
+// masked keeps a nil value, which only says the employee did not exist.
+func masked(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redact.Mask
+}
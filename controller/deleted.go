@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+	"golang-assessment/auth"
+	"golang-assessment/problem"
+	repository "golang-assessment/respository"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isAdmin reports whether the caller may see and restore deleted employees.
+func (ctrl *EmployeeController) isAdmin(c *gin.Context) bool {
+	return slices.Contains(ctrl.softDelete.AdminRoles, auth.RoleFromContext(c.Request.Context()))
+}
+
+// readContext is the context for a read. With ?include_deleted=true, which
+// only admin roles may send, the read also returns deleted employees.
+func (ctrl *EmployeeController) readContext(c *gin.Context) (context.Context, *problem.Problem) {
+	ctx := c.Request.Context()
+	raw, ok := c.GetQuery("include_deleted")
+	if !ok {
+		return ctx, nil
+	}
+	include, err := strconv.ParseBool(raw)
+	if err != nil {
+		return ctx, problem.InvalidQuery("include_deleted", "must be true or false")
+	}
+	if !include {
+		return ctx, nil
+	}
+	if !ctrl.isAdmin(c) {
+		return ctx, problem.New(http.StatusForbidden, problem.CodeForbidden, "include_deleted is only available to admin roles")
+	}
+	return repository.IncludeDeleted(ctx), nil
+}
@@ -1,106 +1,298 @@
 package controller
 
 import (
-	"golang-assessment/logger"
+	"errors"
+	"golang-assessment/auth"
+	"golang-assessment/config"
 	"golang-assessment/models"
+	"golang-assessment/pagination"
+	"golang-assessment/patch"
+	"golang-assessment/problem"
+	"golang-assessment/redact"
+	repository "golang-assessment/respository"
 	"golang-assessment/services"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 type EmployeeController struct {
-	service *services.EmployeeService
+	service     *services.EmployeeService
+	policy      *redact.Policy
+	limits      pagination.Limits
+	concurrency config.ConcurrencyConfig
+	softDelete  config.SoftDeleteConfig
+	cursors     *pagination.CursorCodec
+	log         *logrus.Logger
 }
 
-func NewEmployeeController(service *services.EmployeeService) *EmployeeController {
-	return &EmployeeController{service: service}
+func NewEmployeeController(service *services.EmployeeService, policy *redact.Policy, limits pagination.Limits, concurrency config.ConcurrencyConfig, softDelete config.SoftDeleteConfig, cursors *pagination.CursorCodec, log *logrus.Logger) *EmployeeController {
+	return &EmployeeController{service: service, policy: policy, limits: limits, concurrency: concurrency, softDelete: softDelete, cursors: cursors, log: log}
+}
+
+// visible returns v without the fields the caller's role may not see.
+func (ctrl *EmployeeController) visible(c *gin.Context, v interface{}) interface{} {
+	return ctrl.policy.Response(auth.RoleFromContext(c.Request.Context()), v)
+}
+
+func (ctrl *EmployeeController) render(c *gin.Context, status int, v interface{}) {
+	c.JSON(status, ctrl.visible(c, v))
+}
+
+// renderFields is render for reads that honour ?fields=. Fields the caller's
+// role may not see stay hidden even when asked for.
+func (ctrl *EmployeeController) renderFields(c *gin.Context, status int, v interface{}, fields repository.Fields) {
+	c.JSON(status, pick(ctrl.visible(c, v), fields))
 }
 
 func (ctrl *EmployeeController) CreateEmployee(c *gin.Context) {
-	var employee models.Employee
-	if err := c.ShouldBindJSON(&employee); err != nil {
-		logger.Log.Errorf("Error binding JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request EmployeeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error binding JSON: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
+		return
+	}
+	newEmployee, err := ctrl.service.CreateEmployee(c.Request.Context(), request.EmployeeInput)
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error creating employee: %v", err)
+		problem.Fail(c, err)
 		return
 	}
-	newEmployee := ctrl.service.CreateEmployee(employee.Name, employee.Position, employee.Salary)
-	logger.Log.Infof("Created employee: %v", newEmployee)
-	c.JSON(http.StatusCreated, newEmployee)
+	ctrl.log.WithContext(c.Request.Context()).WithField("employee_id", newEmployee.ID).Info("Created employee")
+	c.Header("ETag", etag(newEmployee.Version))
+	c.Header("Location", employeeURL(newEmployee.ID))
+	ctrl.render(c, http.StatusCreated, newEmployeeResponse(newEmployee))
 }
 
 func (ctrl *EmployeeController) GetEmployeeByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Log.Errorf("Invalid ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Invalid ID: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid ID"))
 		return
 	}
-	employee, err := ctrl.service.GetEmployeeByID(id)
+	fields, paramErr := parseFields(c)
+	if paramErr != nil {
+		problem.Fail(c, paramErr)
+		return
+	}
+	ctx, paramErr := ctrl.readContext(c)
+	if paramErr != nil {
+		problem.Fail(c, paramErr)
+		return
+	}
+	employee, err := ctrl.service.GetEmployeeByID(ctx, id, fields)
 	if err != nil {
-		logger.Log.Errorf("Error retrieving employee by ID %d: %v", id, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error retrieving employee by ID %d: %v", id, err)
+		problem.Fail(c, err)
 		return
 	}
-	logger.Log.Infof("Retrieved employee: %v", employee)
-	c.JSON(http.StatusOK, employee)
+	ctrl.log.WithContext(c.Request.Context()).WithField("employee_id", employee.ID).Debug("Retrieved employee")
+	c.Header("ETag", etag(employee.Version))
+	ctrl.renderFields(c, http.StatusOK, newEmployeeResponse(employee), fields)
 }
 
 func (ctrl *EmployeeController) UpdateEmployee(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Log.Errorf("Invalid ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Invalid ID: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid ID"))
+		return
+	}
+	var request EmployeeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error binding JSON: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
+		return
+	}
+	ifMatch, ok := ctrl.preconditions(c)
+	if !ok {
+		return
+	}
+	updatedEmployee, err := ctrl.service.UpdateEmployee(c.Request.Context(), id, ifMatch, request.EmployeeInput)
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error updating employee by ID %d: %v", id, err)
+		problem.Fail(c, err)
+		return
+	}
+	ctrl.log.WithContext(c.Request.Context()).WithField("employee_id", updatedEmployee.ID).Info("Updated employee")
+	c.Header("ETag", etag(updatedEmployee.Version))
+	ctrl.render(c, http.StatusOK, newEmployeeResponse(updatedEmployee))
+}
+
+// PatchEmployee applies a JSON Merge Patch or JSON Patch, chosen by the
+// Content-Type, and changes only the fields the patch touches.
+func (ctrl *EmployeeController) PatchEmployee(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Invalid ID: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid ID"))
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidBody, err.Error()))
 		return
 	}
-	var employee models.Employee
-	if err := c.ShouldBindJSON(&employee); err != nil {
-		logger.Log.Errorf("Error binding JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ifMatch, ok := ctrl.preconditions(c)
+	if !ok {
+		return
+	}
+	p, err := patch.Parse(c.ContentType(), body)
+	if errors.Is(err, patch.ErrUnsupportedType) {
+		c.Header("Accept-Patch", patch.MergePatchType+", "+patch.JSONPatchType)
+		problem.Fail(c, problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, err.Error()))
 		return
 	}
-	updatedEmployee, err := ctrl.service.UpdateEmployee(id, employee.Name, employee.Position, employee.Salary)
 	if err != nil {
-		logger.Log.Errorf("Error updating employee by ID %d: %v", id, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctrl.log.WithContext(c.Request.Context()).Warnf("Invalid patch: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidPatch, err.Error()))
 		return
 	}
-	logger.Log.Infof("Updated employee: %v", updatedEmployee)
-	c.JSON(http.StatusOK, updatedEmployee)
+
+	patchedEmployee, err := ctrl.service.PatchEmployee(c.Request.Context(), id, ifMatch, p)
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error patching employee by ID %d: %v", id, err)
+		problem.Fail(c, err)
+		return
+	}
+	ctrl.log.WithContext(c.Request.Context()).WithField("employee_id", patchedEmployee.ID).Info("Patched employee")
+	c.Header("ETag", etag(patchedEmployee.Version))
+	ctrl.render(c, http.StatusOK, newEmployeeResponse(patchedEmployee))
 }
 
 func (ctrl *EmployeeController) DeleteEmployee(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Log.Errorf("Invalid ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Invalid ID: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid ID"))
+		return
+	}
+	ifMatch, ok := ctrl.preconditions(c)
+	if !ok {
 		return
 	}
-	if err := ctrl.service.DeleteEmployee(id); err != nil {
-		logger.Log.Errorf("Error deleting employee by ID %d: %v", id, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if err := ctrl.service.DeleteEmployee(c.Request.Context(), id, ifMatch); err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error deleting employee by ID %d: %v", id, err)
+		problem.Fail(c, err)
 		return
 	}
-	logger.Log.Infof("Deleted employee with ID: %d", id)
+	ctrl.log.WithContext(c.Request.Context()).Infof("Deleted employee with ID: %d", id)
 	c.JSON(http.StatusOK, gin.H{"data": "Successfully deleted the employee"})
 }
 
+// RestoreEmployee undoes the soft delete of an employee. Only admin roles may
+// restore, and like other writes it honours If-Match; the ETag of a deleted
+// employee comes from a read with ?include_deleted=true.
+func (ctrl *EmployeeController) RestoreEmployee(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Invalid ID: %v", err)
+		problem.Fail(c, problem.New(http.StatusBadRequest, problem.CodeInvalidID, "invalid ID"))
+		return
+	}
+	if !ctrl.isAdmin(c) {
+		problem.Fail(c, problem.New(http.StatusForbidden, problem.CodeForbidden, "only admin roles may restore employees"))
+		return
+	}
+	ifMatch, ok := ctrl.preconditions(c)
+	if !ok {
+		return
+	}
+	restoredEmployee, err := ctrl.service.RestoreEmployee(c.Request.Context(), id, ifMatch)
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error restoring employee by ID %d: %v", id, err)
+		problem.Fail(c, err)
+		return
+	}
+	ctrl.log.WithContext(c.Request.Context()).WithField("employee_id", restoredEmployee.ID).Info("Restored employee")
+	c.Header("ETag", etag(restoredEmployee.Version))
+	ctrl.render(c, http.StatusOK, newEmployeeResponse(restoredEmployee))
+}
+
+// ListEmployees pages by ?page= or, when ?cursor= is present, walks the
+// employees with encrypted cursors. An empty cursor starts at the beginning.
+// Both modes take the filter and sort parameters of parseListQuery.
 func (ctrl *EmployeeController) ListEmployees(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
+	query, paramErr := parseListQuery(c, ctrl.policy)
+	if paramErr != nil {
+		ctrl.log.WithContext(c.Request.Context()).Warnf("Invalid list query: %v", paramErr)
+		problem.Fail(c, paramErr)
+		return
+	}
+	limit, err := ctrl.limits.Limit(c.Query("limit"))
+	if err != nil {
+		problem.Fail(c, problem.InvalidQuery("limit", err.Error()))
+		return
 	}
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil || limit < 1 {
-		limit = 10
+	ctx, paramErr := ctrl.readContext(c)
+	if paramErr != nil {
+		problem.Fail(c, paramErr)
+		return
+	}
+	// Both list modes read with the request's context
+	c.Request = c.Request.WithContext(ctx)
+	if token, ok := c.GetQuery("cursor"); ok {
+		ctrl.listEmployeesAfter(c, query, token, limit)
+		return
+	}
+	page, err := pagination.Page(c.Query("page"), limit)
+	if err != nil {
+		problem.Fail(c, problem.InvalidQuery("page", err.Error()))
+		return
+	}
+	employees, err := ctrl.service.ListEmployees(c.Request.Context(), query, page, limit)
+	if err != nil {
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error listing employees: %v", err)
+		problem.Fail(c, err)
+		return
 	}
-	employees, err := ctrl.service.ListEmployees(page, limit)
+	ctrl.log.WithContext(c.Request.Context()).WithField("count", len(employees.Employees)).Debug("Listed employees")
+
+	data := newEmployeeResponses(employees.Employees)
+	response := newListResponse(c, employees, pick(ctrl.visible(c, data), query.Fields))
+	c.Header("Link", response.Links.header())
+	c.JSON(http.StatusOK, response)
+}
+
+func (ctrl *EmployeeController) listEmployeesAfter(c *gin.Context, query repository.ListQuery, token string, limit int) {
+	if _, ok := c.GetQuery("page"); ok {
+		problem.Fail(c, problem.InvalidQuery("page", "cannot be combined with cursor"))
+		return
+	}
+	var after *models.Employee
+	if token != "" {
+		cursor, err := ctrl.cursors.Decode(token)
+		if err == nil && cursor.Query != query.String() {
+			err = errors.New("cursor was issued for different filters or sort")
+		}
+		if err == nil {
+			after, err = query.After(cursor.Key)
+		}
+		if err != nil {
+			ctrl.log.WithContext(c.Request.Context()).Warnf("Rejected cursor: %v", err)
+			problem.Fail(c, problem.InvalidQuery("cursor", pagination.ErrInvalidCursor.Error()))
+			return
+		}
+	}
+
+	employees, err := ctrl.service.ListEmployeesAfter(c.Request.Context(), query, after, limit)
 	if err != nil {
-		logger.Log.Errorf("Error listing employees: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctrl.log.WithContext(c.Request.Context()).Errorf("Error listing employees: %v", err)
+		problem.Fail(c, err)
 		return
 	}
-	logger.Log.Infof("Listed employees: %v", employees)
-	c.JSON(http.StatusOK, employees)
+	ctrl.log.WithContext(c.Request.Context()).WithField("count", len(employees.Employees)).Debug("Listed employees")
+
+	var next string
+	if employees.HasNext {
+		last := employees.Employees[len(employees.Employees)-1]
+		next = ctrl.cursors.Encode(pagination.Cursor{Key: query.KeyOf(last), Query: query.String()})
+	}
+	data := newEmployeeResponses(employees.Employees)
+	response := newCursorListResponse(c, employees.Limit, next, pick(ctrl.visible(c, data), query.Fields))
+	c.Header("Link", response.Links.header())
+	c.JSON(http.StatusOK, response)
 }
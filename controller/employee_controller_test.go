@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -8,44 +10,81 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 
+	"golang-assessment/auth"
+	"golang-assessment/config"
 	loggerNew "golang-assessment/logger"
 	"golang-assessment/models"
+	"golang-assessment/pagination"
+	"golang-assessment/problem"
+	"golang-assessment/redact"
 	repository "golang-assessment/respository"
 	"golang-assessment/services"
+	"golang-assessment/validation"
 )
 
-func setupTestLogger(t *testing.T) {
-	// Initialize a buffer to capture log output
-	logger := logrus.New()
-	// Replace the global logger with the mock logger
-	loggerNew.Log = logger
+func setupTestStore(t *testing.T) repository.EmployeeStore {
+	// Seed an in-memory store so the tests do not need a database
+	store := repository.NewMemoryEmployeeStore(loggerNew.Discard())
+	seed := []models.Employee{
+		{Name: "John Doe", Position: "Developer", Salary: 60000},
+		{Name: "Jane Doe", Position: "Manager", Salary: 60000},
+	}
+	for i := range seed {
+		store.CreateEmployee(context.Background(), &seed[i])
+	}
+	return store
+}
+
+// testControllerOptions overrides what newTestController builds the
+// controller with. Zero fields keep the defaults.
+type testControllerOptions struct {
+	store       repository.EmployeeStore
+	redaction   *config.RedactionConfig
+	limits      *pagination.Limits
+	concurrency config.ConcurrencyConfig
+	softDelete  *config.SoftDeleteConfig
 }
-func setupTestDB(t *testing.T) *gorm.DB {
-	// Connect to your test database
-	dsn := "host=localhost user=postgres password=root123 dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Shanghai"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("error opening database: %v", err)
+
+// newTestController builds an EmployeeController on a real service, by
+// default over a fresh setupTestStore.
+func newTestController(t *testing.T, opts testControllerOptions) *EmployeeController {
+	defaults := config.DefaultConfig()
+	if opts.store == nil {
+		opts.store = setupTestStore(t)
+	}
+	if opts.redaction == nil {
+		opts.redaction = &defaults.Redaction
+	}
+	if opts.limits == nil {
+		limits := pagination.NewLimits(defaults.Pagination)
+		opts.limits = &limits
 	}
-	return db
+	if opts.softDelete == nil {
+		opts.softDelete = &defaults.SoftDelete
+	}
+	service := services.NewEmployeeService(opts.store, validation.New(defaults.Validation))
+	return NewEmployeeController(service, redact.NewPolicy(*opts.redaction), *opts.limits, opts.concurrency, *opts.softDelete, pagination.NewCursorCodec([]byte("test")), loggerNew.Discard())
+}
+
+// setupTestRouter authenticates every request with the default (disabled)
+// auth config, so callers get the default role.
+func setupTestRouter() *gin.Engine {
+	router := gin.Default()
+	router.Use(problem.Middleware(), auth.Middleware(config.DefaultConfig().Auth))
+	return router
 }
+
 func TestCreateEmployee(t *testing.T) {
 	// Setup
-	setupTestLogger(t)
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo) // Create a real service instance
-	controller := NewEmployeeController(service)
+	repo := setupTestStore(t)
+	controller := newTestController(t, testControllerOptions{store: repo})
 
 	// Test CreateEmployee
 	t.Run("TestCreateEmployee", func(t *testing.T) {
 		// Prepare request data
-		employee := models.Employee{Name: "John Doe", Position: "Software Engineer", Salary: 50000}
+		employee := services.EmployeeInput{Name: "John Doe", Position: "Software Engineer", Salary: 50000}
 		jsonStr, _ := json.Marshal(employee)
 		req, _ := http.NewRequest("POST", "/employees", strings.NewReader(string(jsonStr)))
 		req.Header.Set("Content-Type", "application/json")
@@ -54,7 +93,7 @@ func TestCreateEmployee(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.POST("/employees", controller.CreateEmployee)
 		router.ServeHTTP(rr, req)
 
@@ -62,32 +101,55 @@ func TestCreateEmployee(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, rr.Code)
 
 		// Assert response body
-		var createdEmployee models.Employee
+		var createdEmployee EmployeeResponse
 		err := json.Unmarshal(rr.Body.Bytes(), &createdEmployee)
 		assert.Nil(t, err)
 		assert.Equal(t, employee.Name, createdEmployee.Name)
+		assert.False(t, createdEmployee.CreatedAt.IsZero())
+		assert.Equal(t, createdEmployee.CreatedAt, createdEmployee.UpdatedAt)
+		assert.Equal(t, "/employees/3", createdEmployee.Links.Self)
+		assert.Equal(t, "/employees/3", rr.Header().Get("Location"))
 	})
 
+	// Test case: Every failing field is listed
+	t.Run("TestCreateEmployee_Invalid", func(t *testing.T) {
+		body := `{"id":7,"name":"  ","position":"` + strings.Repeat("x", 101) + `","salary":-5}`
+		req, _ := http.NewRequest("POST", "/employees", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router := setupTestRouter()
+		router.POST("/employees", controller.CreateEmployee)
+		router.ServeHTTP(rr, req)
+
+		assertProblem(t, rr, http.StatusUnprocessableEntity, gin.H{
+			"code": "validation_failed",
+			"errors": []interface{}{
+				map[string]interface{}{"field": "id", "message": "is assigned by the server and cannot be set"},
+				map[string]interface{}{"field": "name", "message": "is required"},
+				map[string]interface{}{"field": "position", "message": "must be at most 100 characters long"},
+				map[string]interface{}{"field": "salary", "message": "must be greater than 0"},
+			},
+		})
+		count, _ := repo.CountEmployees(context.Background())
+		assert.Equal(t, int64(3), count)
+	})
 }
 
 func TestGetEmployeeByID(t *testing.T) {
 	// Setup
-	setupTestLogger(t)
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
-	controller := NewEmployeeController(service)
+	controller := newTestController(t, testControllerOptions{})
 
 	// Test case: Valid employee ID
 	t.Run("TestGetEmployeeByID_ValidID", func(t *testing.T) {
 		// Prepare request
-		req, err := http.NewRequest("GET", "/employees/6", nil)
+		req, err := http.NewRequest("GET", "/employees/1", nil)
 		assert.Nil(t, err)
 		// Create a response recorder
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.GET("/employees/:id", controller.GetEmployeeByID)
 		router.ServeHTTP(rr, req)
 
@@ -98,7 +160,7 @@ func TestGetEmployeeByID(t *testing.T) {
 		var actualEmployee models.Employee
 		err = json.Unmarshal(rr.Body.Bytes(), &actualEmployee)
 		assert.Nil(t, err)
-		assert.Equal(t, 6, actualEmployee.ID) // Assuming the ID is returned in the response
+		assert.Equal(t, 1, actualEmployee.ID) // Assuming the ID is returned in the response
 	})
 
 	t.Run("TestGetEmployeeByID_InvalidID", func(t *testing.T) {
@@ -109,7 +171,7 @@ func TestGetEmployeeByID(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.GET("/employees/:id", controller.GetEmployeeByID)
 		router.ServeHTTP(rr, req)
 
@@ -121,27 +183,21 @@ func TestGetEmployeeByID(t *testing.T) {
 
 func TestUpdateEmployee(t *testing.T) {
 	// Setup
-	setupTestLogger(t)
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
-	controller := NewEmployeeController(service)
+	controller := newTestController(t, testControllerOptions{})
 	// Test case: Valid employee update
 	t.Run("TestUpdateEmployee_ValidData", func(t *testing.T) {
-		// Stub service method to return a hardcoded updated employee
-		expectedEmployee := &models.Employee{ID: 6, Name: "Updated Name", Position: "Updated Position", Salary: 60000}
 
 		// Prepare request data
-		updatedEmployee := models.Employee{Name: "Updated Name", Position: "Updated Position", Salary: 60000}
+		updatedEmployee := services.EmployeeInput{Name: "Updated Name", Position: "Updated Position", Salary: 60000}
 		jsonStr, _ := json.Marshal(updatedEmployee)
-		req, _ := http.NewRequest("PUT", "/employees/6", strings.NewReader(string(jsonStr)))
+		req, _ := http.NewRequest("PUT", "/employees/1", strings.NewReader(string(jsonStr)))
 		req.Header.Set("Content-Type", "application/json")
 
 		// Create a response recorder
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.PUT("/employees/:id", controller.UpdateEmployee)
 		router.ServeHTTP(rr, req)
 
@@ -149,10 +205,7 @@ func TestUpdateEmployee(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 
 		// Assert response body
-		var actualEmployee models.Employee
-		err := json.Unmarshal(rr.Body.Bytes(), &actualEmployee)
-		assert.Nil(t, err)
-		assert.Equal(t, expectedEmployee, &actualEmployee)
+		assertEmployeeJSON(t, `{"id":1,"name":"Updated Name","position":"Updated Position","salary":60000,"created_by":"","updated_by":"anonymous","links":{"self":"/employees/1"}}`, rr.Body.Bytes())
 	})
 
 	// Test case: Invalid request data
@@ -165,7 +218,7 @@ func TestUpdateEmployee(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.PUT("/employees/:id", controller.UpdateEmployee)
 		router.ServeHTTP(rr, req)
 
@@ -174,24 +227,147 @@ func TestUpdateEmployee(t *testing.T) {
 	})
 }
 
+func TestPatchEmployee(t *testing.T) {
+	// Setup
+	controller := newTestController(t, testControllerOptions{})
+	router := setupTestRouter()
+	router.PATCH("/employees/:id", controller.PatchEmployee)
+	send := func(target, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("TestPatchEmployee_MergePatch", func(t *testing.T) {
+		rr := send("/employees/1", "application/merge-patch+json; charset=utf-8", `{"name":"John Smith"}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assertEmployeeJSON(t, `{"id":1,"name":"John Smith","position":"Developer","salary":60000,"created_by":"","updated_by":"anonymous","links":{"self":"/employees/1"}}`, rr.Body.Bytes())
+	})
+
+	t.Run("TestPatchEmployee_JSONPatch", func(t *testing.T) {
+		rr := send("/employees/2", "application/json-patch+json", `[{"op":"replace","path":"/position","value":"Director"}]`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assertEmployeeJSON(t, `{"id":2,"name":"Jane Doe","position":"Director","salary":60000,"created_by":"","updated_by":"anonymous","links":{"self":"/employees/2"}}`, rr.Body.Bytes())
+	})
+
+	t.Run("TestPatchEmployee_UnsupportedMediaType", func(t *testing.T) {
+		rr := send("/employees/1", "application/json", `{"name":"John Smith"}`)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+		assert.Equal(t, "application/merge-patch+json, application/json-patch+json", rr.Header().Get("Accept-Patch"))
+	})
+
+	t.Run("TestPatchEmployee_MalformedPatch", func(t *testing.T) {
+		rr := send("/employees/1", "application/json-patch+json", `[{"op":"rename","path":"/name"}]`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("TestPatchEmployee_Unprocessable", func(t *testing.T) {
+		rr := send("/employees/1", "application/merge-patch+json", `{"salary":null}`)
+
+		assertProblem(t, rr, http.StatusUnprocessableEntity, gin.H{"code": "validation_failed", "detail": "patched employee is invalid: salary cannot be removed"})
+	})
+
+	t.Run("TestPatchEmployee_NotFound", func(t *testing.T) {
+		rr := send("/employees/100", "application/merge-patch+json", `{"name":"Nobody"}`)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	// Setup
+	setup := func(concurrency config.ConcurrencyConfig) *gin.Engine {
+		controller := newTestController(t, testControllerOptions{concurrency: concurrency})
+		router := setupTestRouter()
+		router.GET("/employees/:id", controller.GetEmployeeByID)
+		router.PUT("/employees/:id", controller.UpdateEmployee)
+		router.PATCH("/employees/:id", controller.PatchEmployee)
+		router.DELETE("/employees/:id", controller.DeleteEmployee)
+		return router
+	}
+	send := func(router *gin.Engine, method, target, ifMatch, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if method == "PATCH" {
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+		}
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	update := `{"name":"John Doe","position":"Lead","salary":70000}`
+
+	t.Run("TestOptimisticConcurrency_ETag", func(t *testing.T) {
+		router := setup(config.ConcurrencyConfig{})
+
+		rr := send(router, "GET", "/employees/1", "", "")
+		assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+
+		rr = send(router, "PUT", "/employees/1", `"1"`, update)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		rr = send(router, "PATCH", "/employees/1", `"2"`, `{"position":"Director"}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("TestOptimisticConcurrency_StaleIfMatch", func(t *testing.T) {
+		router := setup(config.ConcurrencyConfig{})
+		assert.Equal(t, http.StatusOK, send(router, "PUT", "/employees/1", `"1"`, update).Code)
+
+		// A second editor still holding version 1 must not overwrite version 2
+		for _, method := range []string{"PUT", "PATCH", "DELETE"} {
+			rr := send(router, method, "/employees/1", `"1"`, update)
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code, method)
+		}
+		rr := send(router, "GET", "/employees/1", "", "")
+		assertEmployeeJSON(t, `{"id":1,"name":"John Doe","position":"Lead","salary":70000,"created_by":"","updated_by":"anonymous","links":{"self":"/employees/1"}}`, rr.Body.Bytes())
+	})
+
+	t.Run("TestOptimisticConcurrency_IfMatchForms", func(t *testing.T) {
+		router := setup(config.ConcurrencyConfig{})
+
+		assert.Equal(t, http.StatusPreconditionFailed, send(router, "PUT", "/employees/1", `W/"1"`, update).Code)
+		assert.Equal(t, http.StatusOK, send(router, "PUT", "/employees/1", `"7", "1"`, update).Code)
+		assert.Equal(t, http.StatusOK, send(router, "DELETE", "/employees/1", "*", "").Code)
+		assert.Equal(t, http.StatusNotFound, send(router, "DELETE", "/employees/1", "*", "").Code)
+	})
+
+	t.Run("TestOptimisticConcurrency_RequireIfMatch", func(t *testing.T) {
+		router := setup(config.ConcurrencyConfig{RequireIfMatch: true})
+
+		for _, method := range []string{"PUT", "PATCH", "DELETE"} {
+			rr := send(router, method, "/employees/1", "", update)
+			assert.Equal(t, http.StatusPreconditionRequired, rr.Code, method)
+		}
+		assert.Equal(t, http.StatusOK, send(router, "DELETE", "/employees/1", `"1"`, "").Code)
+	})
+}
+
 func TestDeleteEmployee(t *testing.T) {
 	// Setup
-	setupTestLogger(t)
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
-	controller := NewEmployeeController(service)
+	controller := newTestController(t, testControllerOptions{})
 
 	// Test case: Valid employee deletion
 	t.Run("TestDeleteEmployee_ValidID", func(t *testing.T) {
 		// Prepare request
-		req, _ := http.NewRequest("DELETE", "/employees/11", nil)
+		req, _ := http.NewRequest("DELETE", "/employees/1", nil)
 
 		// Create a response recorder
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.DELETE("/employees/:id", controller.DeleteEmployee)
 		router.ServeHTTP(rr, req)
 
@@ -213,19 +389,42 @@ func TestDeleteEmployee(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.DELETE("/employees/:id", controller.DeleteEmployee)
 		router.ServeHTTP(rr, req)
 
-		// Assert response status code
-		assert.Equal(t, http.StatusNotFound, rr.Code)
-
-		// Assert response body
-		expectedBody := gin.H{"error": "Employee not found"}
-		assertResponseBody(t, rr.Body.Bytes(), expectedBody)
+		// Assert response status code and body
+		assertProblem(t, rr, http.StatusBadRequest, gin.H{"code": "invalid_id", "detail": "invalid ID", "instance": "/employees/invalid_id"})
 	})
 }
 
+// assertProblem checks that rr is a problem+json response with the given
+// status and, for every key of expected, the same member value.
+func assertProblem(t *testing.T, rr *httptest.ResponseRecorder, status int, expected gin.H) {
+	t.Helper()
+	assert.Equal(t, status, rr.Code)
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+	var actual gin.H
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &actual))
+	for key, value := range expected {
+		assert.Equal(t, value, actual[key], key)
+	}
+}
+
+// assertEmployeeJSON is assert.JSONEq for a rendered employee, once its
+// timestamps have been checked and removed.
+func assertEmployeeJSON(t *testing.T, expected string, body []byte) {
+	t.Helper()
+	var actual gin.H
+	assert.Nil(t, json.Unmarshal(body, &actual))
+	for _, field := range []string{"created_at", "updated_at"} {
+		assert.NotEmpty(t, actual[field], field)
+		delete(actual, field)
+	}
+	raw, _ := json.Marshal(actual)
+	assert.JSONEq(t, expected, string(raw))
+}
+
 // Helper function to assert response body
 func assertResponseBody(t *testing.T, body []byte, expected gin.H) {
 	var actual gin.H
@@ -236,11 +435,7 @@ func assertResponseBody(t *testing.T, body []byte, expected gin.H) {
 
 func TestListEmployees(t *testing.T) {
 	// Setup
-	setupTestLogger(t)
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
-	controller := NewEmployeeController(service)
+	controller := newTestController(t, testControllerOptions{})
 
 	t.Run("TestListEmployees", func(t *testing.T) {
 		// Prepare request
@@ -251,7 +446,7 @@ func TestListEmployees(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		// Create a test context with the request and response recorder
-		router := gin.Default()
+		router := setupTestRouter()
 		router.GET("/employees", controller.ListEmployees)
 		router.ServeHTTP(rr, req)
 
@@ -259,9 +454,447 @@ func TestListEmployees(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 
 		// Assert response body
-		var actualEmployees []models.Employee
-		err := json.Unmarshal(rr.Body.Bytes(), &actualEmployees)
+		var response struct {
+			Data       []models.Employee `json:"data"`
+			Page       int               `json:"page"`
+			Limit      int               `json:"limit"`
+			Total      int64             `json:"total"`
+			TotalPages int               `json:"total_pages"`
+			HasNext    bool              `json:"has_next"`
+		}
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
 		assert.Nil(t, err)
-		assert.NotNil(t, actualEmployees)
+		assert.Len(t, response.Data, 2)
+		assert.Equal(t, 1, response.Page)
+		assert.Equal(t, 10, response.Limit)
+		assert.Equal(t, int64(2), response.Total)
+		assert.Equal(t, 1, response.TotalPages)
+		assert.False(t, response.HasNext)
+	})
+
+	t.Run("TestListEmployees_Links", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/employees?limit=1&page=2&sort=name", nil)
+		rr := httptest.NewRecorder()
+
+		router := setupTestRouter()
+		router.GET("/employees", controller.ListEmployees)
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response struct {
+			HasNext bool              `json:"has_next"`
+			Links   map[string]string `json:"links"`
+		}
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.False(t, response.HasNext)
+		assert.Equal(t, map[string]string{
+			"first": "/employees?limit=1&page=1&sort=name",
+			"last":  "/employees?limit=1&page=2&sort=name",
+			"prev":  "/employees?limit=1&page=1&sort=name",
+		}, response.Links)
+		assert.Equal(t, `</employees?limit=1&page=1&sort=name>; rel="first", </employees?limit=1&page=1&sort=name>; rel="prev", </employees?limit=1&page=2&sort=name>; rel="last"`, rr.Header().Get("Link"))
+	})
+
+	t.Run("TestListEmployees_PastTheEnd", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/employees?page=5", nil)
+		rr := httptest.NewRecorder()
+
+		router := setupTestRouter()
+		router.GET("/employees", controller.ListEmployees)
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"data": [],
+			"page": 5,
+			"limit": 10,
+			"total": 2,
+			"total_pages": 1,
+			"has_next": false,
+			"links": {"first": "/employees?page=1", "last": "/employees?page=1", "prev": "/employees?page=1"}
+		}`, rr.Body.String())
+	})
+}
+
+func TestListEmployees_Limits(t *testing.T) {
+	// Setup
+	get := func(limits pagination.Limits, target string) *httptest.ResponseRecorder {
+		controller := newTestController(t, testControllerOptions{limits: &limits})
+		req, _ := http.NewRequest("GET", target, nil)
+		rr := httptest.NewRecorder()
+		router := setupTestRouter()
+		router.GET("/employees", controller.ListEmployees)
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	limits := pagination.Limits{Default: 1, Max: 5}
+
+	t.Run("TestListEmployees_DefaultLimit", func(t *testing.T) {
+		rr := get(limits, "/employees")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body listResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.Limit)
+		assert.Len(t, body.Data, 1)
+	})
+
+	t.Run("TestListEmployees_InvalidPageAndLimit", func(t *testing.T) {
+		for target, expected := range map[string]gin.H{
+			"/employees?page=abc":         {"detail": "page: must be a positive integer", "parameter": "page"},
+			"/employees?page=0":           {"detail": "page: must be a positive integer", "parameter": "page"},
+			"/employees?limit=abc":        {"detail": "limit: must be a positive integer", "parameter": "limit"},
+			"/employees?limit=-1":         {"detail": "limit: must be a positive integer", "parameter": "limit"},
+			"/employees?limit=10000000":   {"detail": "limit: must not exceed 5", "parameter": "limit"},
+			"/employees?cursor=&limit=10": {"detail": "limit: must not exceed 5", "parameter": "limit"},
+		} {
+			rr := get(limits, target)
+			expected["code"] = "invalid_query"
+			assertProblem(t, rr, http.StatusBadRequest, expected)
+		}
+	})
+
+	t.Run("TestListEmployees_ClampedLimit", func(t *testing.T) {
+		limits := limits
+		limits.Clamp = true
+		rr := get(limits, "/employees?limit=10000000")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body listResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, 5, body.Limit)
+	})
+}
+
+func TestSalaryRedaction(t *testing.T) {
+	// Setup
+	controller := newTestController(t, testControllerOptions{redaction: &config.RedactionConfig{SalaryRoles: []string{"hr"}}})
+	router := gin.Default()
+	router.Use(problem.Middleware(), auth.Middleware(config.AuthConfig{
+		Enabled: true,
+		Tokens:  map[string]config.Principal{"hr-token": {Subject: "hannah", Role: "hr"}, "viewer-token": {Subject: "victor", Role: "viewer"}},
+	}))
+	router.GET("/employees/:id", controller.GetEmployeeByID)
+	router.GET("/employees", controller.ListEmployees)
+
+	t.Run("TestSalaryRedaction_AllowedRole", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/employees/1", nil)
+		req.Header.Set("Authorization", "Bearer hr-token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assertEmployeeJSON(t, `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"created_by":"","updated_by":"","links":{"self":"/employees/1"}}`, rr.Body.Bytes())
+	})
+
+	t.Run("TestSalaryRedaction_OtherRole", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/employees/1", nil)
+		req.Header.Set("Authorization", "Bearer viewer-token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assertEmployeeJSON(t, `{"id":1,"name":"John Doe","position":"Developer","created_by":"","updated_by":"","links":{"self":"/employees/1"}}`, rr.Body.Bytes())
+	})
+
+	t.Run("TestSalaryRedaction_List", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/employees", nil)
+		req.Header.Set("Authorization", "Bearer viewer-token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "salary")
+	})
+
+	t.Run("TestSalaryRedaction_NoSalaryQueries", func(t *testing.T) {
+		get := func(target, token string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("GET", target, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			return rr
+		}
+
+		// Filters and sorts would reveal a salary without returning it
+		for _, target := range []string{
+			"/employees?salary_gte=60000&salary_lte=60000&fields=name",
+			"/employees?salary_lte=1",
+			"/employees?sort=position,-salary",
+			"/employees?cursor=&limit=1&sort=salary",
+		} {
+			assertProblem(t, get(target, "viewer-token"), http.StatusForbidden, gin.H{"code": "forbidden"})
+			assert.Equal(t, http.StatusOK, get(target, "hr-token").Code, target)
+		}
+
+		// Cursors carry the sort key of the last row, but encrypted
+		var page struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		assert.Nil(t, json.Unmarshal(get("/employees?cursor=&limit=1&sort=salary", "hr-token").Body.Bytes(), &page))
+		assert.NotEmpty(t, page.NextCursor)
+		sealed, err := base64.RawURLEncoding.DecodeString(page.NextCursor)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(sealed), "60000")
+	})
+}
+
+func TestListEmployees_Cursor(t *testing.T) {
+	// Setup
+	repo := setupTestStore(t)
+	controller := newTestController(t, testControllerOptions{store: repo})
+	router := setupTestRouter()
+	router.GET("/employees", controller.ListEmployees)
+
+	type cursorResponse struct {
+		Data       []models.Employee `json:"data"`
+		HasNext    bool              `json:"has_next"`
+		NextCursor string            `json:"next_cursor"`
+	}
+	get := func(t *testing.T, target string) (*httptest.ResponseRecorder, cursorResponse) {
+		req, _ := http.NewRequest("GET", target, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response cursorResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	t.Run("TestListEmployees_CursorWalk", func(t *testing.T) {
+		rr, first := get(t, "/employees?cursor=&limit=1")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, first.Data, 1)
+		assert.Equal(t, 1, first.Data[0].ID)
+		assert.True(t, first.HasNext)
+		assert.Contains(t, rr.Header().Get("Link"), `rel="next"`)
+
+		// Rows deleted or added before the cursor do not shift the walk
+		assert.Nil(t, repo.DeleteEmployee(context.Background(), 1, 1, "admin"))
+
+		rr, second := get(t, "/employees?limit=1&cursor="+first.NextCursor)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, second.Data, 1)
+		assert.Equal(t, 2, second.Data[0].ID)
+		assert.False(t, second.HasNext)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("TestListEmployees_InvalidCursor", func(t *testing.T) {
+		rr, _ := get(t, "/employees?cursor=forged")
+		assertProblem(t, rr, http.StatusBadRequest, gin.H{"code": "invalid_query", "detail": "cursor: invalid cursor", "parameter": "cursor"})
+	})
+
+	t.Run("TestListEmployees_CursorAndPage", func(t *testing.T) {
+		rr, _ := get(t, "/employees?cursor=&page=2")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestListEmployees_FilterAndSort(t *testing.T) {
+	// Setup
+	repo := setupTestStore(t)
+	repo.CreateEmployee(context.Background(), &models.Employee{Name: "Jim Beam", Position: "Developer", Salary: 75000})
+	controller := newTestController(t, testControllerOptions{store: repo})
+	router := setupTestRouter()
+	router.GET("/employees", controller.ListEmployees)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", target, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	names := func(t *testing.T, rr *httptest.ResponseRecorder) []string {
+		var response struct {
+			Data []models.Employee `json:"data"`
+		}
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		var result []string
+		for _, employee := range response.Data {
+			result = append(result, employee.Name)
+		}
+		return result
+	}
+
+	t.Run("TestListEmployees_Filter", func(t *testing.T) {
+		rr := get("/employees?position_prefix=dev&salary_gte=70000")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, []string{"Jim Beam"}, names(t, rr))
+	})
+
+	t.Run("TestListEmployees_Sort", func(t *testing.T) {
+		rr := get("/employees?sort=-salary,name")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, []string{"Jim Beam", "Jane Doe", "John Doe"}, names(t, rr))
+	})
+
+	t.Run("TestListEmployees_SortedCursor", func(t *testing.T) {
+		rr := get("/employees?sort=-salary,name&limit=2&cursor=")
+		assert.Equal(t, []string{"Jim Beam", "Jane Doe"}, names(t, rr))
+		var first struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &first))
+
+		rr = get("/employees?sort=-salary,name&limit=2&cursor=" + first.NextCursor)
+		assert.Equal(t, []string{"John Doe"}, names(t, rr))
+
+		// The cursor only works with the sort it was issued for
+		rr = get("/employees?sort=name&limit=2&cursor=" + first.NextCursor)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("TestListEmployees_InvalidParameters", func(t *testing.T) {
+		for target, parameter := range map[string]string{
+			"/employees?salary_gte=lots":                 "salary_gte",
+			"/employees?salary_gte=100&salary_lte=50":    "salary_lte",
+			"/employees?sort=age":                        "sort",
+			"/employees?sort=name,name":                  "sort",
+			"/employees?salary_lte=NaN&position=Manager": "salary_lte",
+		} {
+			rr := get(target)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+			var body gin.H
+			assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, parameter, body["parameter"], target)
+		}
+	})
+}
+
+func TestSparseFieldsets(t *testing.T) {
+	// Setup
+	controller := newTestController(t, testControllerOptions{redaction: &config.RedactionConfig{SalaryRoles: []string{"hr"}}})
+	router := gin.Default()
+	router.Use(auth.Middleware(config.AuthConfig{
+		Enabled: true,
+		Tokens:  map[string]config.Principal{"hr-token": {Subject: "hannah", Role: "hr"}, "viewer-token": {Subject: "victor", Role: "viewer"}},
+	}))
+	router.GET("/employees/:id", controller.GetEmployeeByID)
+	router.GET("/employees", controller.ListEmployees)
+	get := func(target, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("TestSparseFieldsets_Get", func(t *testing.T) {
+		rr := get("/employees/1?fields=name,position", "hr-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"name":"John Doe","position":"Developer"}`, rr.Body.String())
+	})
+
+	t.Run("TestSparseFieldsets_List", func(t *testing.T) {
+		rr := get("/employees?fields=id,name", "hr-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data  []map[string]interface{} `json:"data"`
+			Links pageLinks                `json:"links"`
+		}
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, []map[string]interface{}{
+			{"id": float64(1), "name": "John Doe"},
+			{"id": float64(2), "name": "Jane Doe"},
+		}, body.Data)
+		assert.Equal(t, "/employees?fields=id%2Cname&page=1", body.Links.First)
+	})
+
+	t.Run("TestSparseFieldsets_SortedCursor", func(t *testing.T) {
+		// The sort key is read for the cursor but not returned
+		rr := get("/employees?fields=name&sort=-salary&limit=1&cursor=", "hr-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var first struct {
+			Data       []map[string]interface{} `json:"data"`
+			NextCursor string                   `json:"next_cursor"`
+		}
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &first))
+		assert.Equal(t, []map[string]interface{}{{"name": "John Doe"}}, first.Data)
+
+		rr = get("/employees?fields=name&sort=-salary&limit=1&cursor="+first.NextCursor, "hr-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"data":[{"name":"Jane Doe"}]`)
+	})
+
+	t.Run("TestSparseFieldsets_HiddenField", func(t *testing.T) {
+		rr := get("/employees/1?fields=name,salary", "viewer-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"name":"John Doe"}`, rr.Body.String())
+	})
+
+	t.Run("TestSparseFieldsets_InvalidFields", func(t *testing.T) {
+		for target, message := range map[string]string{
+			"/employees/1?fields=name,ssn": `fields: unknown field "ssn", selectable fields are created_at, created_by, deleted_at, deleted_by, id, name, position, salary, updated_at, updated_by`,
+			"/employees?fields=name,name":  `fields: "name" appears more than once`,
+			"/employees?fields=":           "fields: must name at least one field",
+			"/employees/1?fields=%20,%20":  "fields: must name at least one field",
+		} {
+			rr := get(target, "hr-token")
+			assertProblem(t, rr, http.StatusBadRequest, gin.H{"code": "invalid_query", "detail": message, "parameter": "fields"})
+		}
+	})
+}
+
+func TestSoftDeleteEndpoints(t *testing.T) {
+	// Setup
+	controller := newTestController(t, testControllerOptions{softDelete: &config.SoftDeleteConfig{AdminRoles: []string{"admin"}}})
+	router := gin.Default()
+	router.Use(problem.Middleware(), auth.Middleware(config.AuthConfig{
+		Enabled: true,
+		Tokens:  map[string]config.Principal{"admin-token": {Subject: "ada", Role: "admin"}, "viewer-token": {Subject: "victor", Role: "viewer"}},
+	}))
+	router.GET("/employees/:id", controller.GetEmployeeByID)
+	router.GET("/employees", controller.ListEmployees)
+	router.DELETE("/employees/:id", controller.DeleteEmployee)
+	router.POST("/employees/:id/restore", controller.RestoreEmployee)
+	send := func(method, target, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	assert.Equal(t, http.StatusOK, send("DELETE", "/employees/1", "viewer-token").Code)
+
+	t.Run("TestSoftDelete_HiddenByDefault", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, send("GET", "/employees/1", "admin-token").Code)
+
+		rr := send("GET", "/employees", "admin-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"total":1`)
+	})
+
+	t.Run("TestSoftDelete_IncludeDeleted", func(t *testing.T) {
+		rr := send("GET", "/employees/1?include_deleted=true", "admin-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var employee EmployeeResponse
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &employee))
+		assert.NotNil(t, employee.DeletedAt)
+		assert.Equal(t, "victor", employee.DeletedBy)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		rr = send("GET", "/employees?include_deleted=true", "admin-token")
+		assert.Contains(t, rr.Body.String(), `"total":2`)
+		rr = send("GET", "/employees?include_deleted=true&cursor=", "admin-token")
+		assert.Contains(t, rr.Body.String(), `"deleted_by":"victor"`)
+	})
+
+	t.Run("TestSoftDelete_IncludeDeletedForbidden", func(t *testing.T) {
+		assertProblem(t, send("GET", "/employees/1?include_deleted=true", "viewer-token"), http.StatusForbidden, gin.H{"code": "forbidden"})
+		assertProblem(t, send("GET", "/employees?include_deleted=true", "viewer-token"), http.StatusForbidden, gin.H{"code": "forbidden"})
+		assertProblem(t, send("GET", "/employees?include_deleted=maybe", "admin-token"), http.StatusBadRequest, gin.H{"code": "invalid_query", "parameter": "include_deleted"})
+		// Asking not to include them needs no permission
+		assert.Equal(t, http.StatusOK, send("GET", "/employees?include_deleted=false", "viewer-token").Code)
+	})
+
+	t.Run("TestRestoreEmployee", func(t *testing.T) {
+		assertProblem(t, send("POST", "/employees/1/restore", "viewer-token"), http.StatusForbidden, gin.H{"code": "forbidden"})
+
+		rr := send("POST", "/employees/1/restore", "admin-token")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+		assertEmployeeJSON(t, `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"created_by":"","updated_by":"ada","links":{"self":"/employees/1"}}`, rr.Body.Bytes())
+		assert.Equal(t, http.StatusOK, send("GET", "/employees/1", "viewer-token").Code)
+
+		assertProblem(t, send("POST", "/employees/1/restore", "admin-token"), http.StatusConflict, gin.H{"code": "conflict", "detail": "employee is not deleted"})
+		assertProblem(t, send("POST", "/employees/9/restore", "admin-token"), http.StatusNotFound, gin.H{"code": "not_found"})
 	})
 }
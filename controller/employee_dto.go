@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"golang-assessment/models"
+	"golang-assessment/services"
+	"strconv"
+	"time"
+)
+
+// The types here are the API's view of an employee. They are kept apart
+// from models.Employee so that the table can change without changing what
+// clients send and receive.
+
+// EmployeeRequest is the body of POST /employees and of PUT /employees/:id,
+// which replaces every field a client may set. Its fields and their validate
+// tags are those of services.EmployeeInput, so the rules are declared once
+// and the service applies them to callers that do not come through HTTP too.
+type EmployeeRequest struct {
+	services.EmployeeInput
+}
+
+// EmployeeResponse is an employee as the API returns it. Fields tagged
+// `sensitive` may be hidden by the redaction policy.
+type EmployeeResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" sensitive:"pii"`
+	Position  string    `json:"position"`
+	Salary    float64   `json:"salary" sensitive:"financial"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedBy string    `json:"created_by"`
+	UpdatedBy string    `json:"updated_by"`
+	// DeletedAt and DeletedBy are only set on deleted employees, which are
+	// only listed with ?include_deleted=true.
+	DeletedAt *time.Time    `json:"deleted_at,omitempty"`
+	DeletedBy string        `json:"deleted_by,omitempty"`
+	Links     employeeLinks `json:"links"`
+}
+
+type employeeLinks struct {
+	Self string `json:"self"`
+}
+
+func newEmployeeResponse(employee models.Employee) EmployeeResponse {
+	response := EmployeeResponse{
+		ID:        employee.ID,
+		Name:      employee.Name,
+		Position:  employee.Position,
+		Salary:    employee.Salary,
+		CreatedAt: employee.CreatedAt,
+		UpdatedAt: employee.UpdatedAt,
+		CreatedBy: employee.CreatedBy,
+		UpdatedBy: employee.UpdatedBy,
+		DeletedBy: employee.DeletedBy,
+		Links:     employeeLinks{Self: employeeURL(employee.ID)},
+	}
+	if employee.IsDeleted() {
+		response.DeletedAt = &employee.DeletedAt.Time
+	}
+	return response
+}
+
+// newEmployeeResponses maps a page of employees; an empty page is an empty
+// list rather than null.
+func newEmployeeResponses(employees []models.Employee) []EmployeeResponse {
+	responses := make([]EmployeeResponse, len(employees))
+	for i, employee := range employees {
+		responses[i] = newEmployeeResponse(employee)
+	}
+	return responses
+}
+
+func employeeURL(id int) string {
+	return "/employees/" + strconv.Itoa(id)
+}
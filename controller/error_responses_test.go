@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-assessment/apperrors"
+	"golang-assessment/logger"
+	"golang-assessment/models"
+	repository "golang-assessment/respository"
+)
+
+// failingStore fails every create and read with err.
+type failingStore struct {
+	repository.EmployeeStore
+	err error
+}
+
+// WithTx hands fn a store that fails the same way.
+func (s *failingStore) WithTx(ctx context.Context, fn func(store repository.EmployeeStore) error) error {
+	return s.EmployeeStore.WithTx(ctx, func(tx repository.EmployeeStore) error {
+		return fn(&failingStore{EmployeeStore: tx, err: s.err})
+	})
+}
+
+func (s *failingStore) CreateEmployee(ctx context.Context, employee *models.Employee) error {
+	return s.err
+}
+
+func (s *failingStore) GetEmployeeByID(ctx context.Context, id int, fields repository.Fields) (models.Employee, error) {
+	return models.Employee{}, s.err
+}
+
+func TestErrorResponses(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail interface{}
+	}{
+		{"NotFound", repository.ErrEmployeeNotFound, http.StatusNotFound, "not_found", "employee not found"},
+		{"Conflict", apperrors.New(apperrors.ErrConflict, "employee already exists"), http.StatusConflict, "conflict", "employee already exists"},
+		{"Validation", apperrors.New(apperrors.ErrValidation, "salary must be positive"), http.StatusUnprocessableEntity, "validation_failed", "salary must be positive"},
+		{"Unavailable", apperrors.Wrap(apperrors.ErrUnavailable, driver.ErrBadConn), http.StatusServiceUnavailable, "unavailable", "the service is temporarily unavailable, try again later"},
+		// Unknown errors may carry SQL, so they have no detail
+		{"Internal", errors.New("no such table: employees"), http.StatusInternalServerError, "internal", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			store := &failingStore{EmployeeStore: setupTestStore(t), err: tc.err}
+			controller := newTestController(t, testControllerOptions{store: store})
+			router := setupTestRouter()
+			router.Use(logger.RequestID())
+			router.POST("/employees", controller.CreateEmployee)
+			router.GET("/employees/:id", controller.GetEmployeeByID)
+
+			req, _ := http.NewRequest("POST", "/employees", strings.NewReader(`{"name":"John Doe","position":"Developer","salary":50000}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(logger.RequestIDHeader, "req-1")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assertProblem(t, rr, tc.status, gin.H{
+				"type":       "/problems/" + tc.code,
+				"title":      http.StatusText(tc.status),
+				"status":     float64(tc.status),
+				"detail":     tc.detail,
+				"instance":   "/employees",
+				"code":       tc.code,
+				"request_id": "req-1",
+			})
+
+			req, _ = http.NewRequest("GET", "/employees/1", nil)
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assertProblem(t, rr, tc.status, gin.H{"code": tc.code, "instance": "/employees/1"})
+		})
+	}
+}
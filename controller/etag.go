@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"golang-assessment/problem"
+	"golang-assessment/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag is the entity tag of a version of an employee.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch reads an If-Match header into the versions it allows. Weak
+// tags never match, as RFC 9110 requires for If-Match, and neither do tags
+// this API did not issue.
+func parseIfMatch(header string) services.IfMatch {
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+	ifMatch := services.IfMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			ifMatch = append(ifMatch, version)
+		}
+	}
+	return ifMatch
+}
+
+// preconditions reads the If-Match header of a write. When the header is
+// required but missing it answers 428 and returns false.
+func (ctrl *EmployeeController) preconditions(c *gin.Context) (services.IfMatch, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if ctrl.concurrency.RequireIfMatch {
+			problem.Fail(c, problem.New(http.StatusPreconditionRequired, problem.CodeIfMatchRequired, "If-Match is required, send the ETag of the employee"))
+			return nil, false
+		}
+		return nil, true
+	}
+	return parseIfMatch(header), true
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"encoding/json"
+	repository "golang-assessment/respository"
+)
+
+// pick keeps only the given JSON fields of a rendered employee, or of each
+// employee in a list. The store may have read more columns than were asked
+// for, e.g. sort keys for a cursor, so the response is trimmed here. Without
+// fields v is returned unchanged.
+func pick(v interface{}, fields repository.Fields) interface{} {
+	if len(fields) == 0 {
+		return v
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var list []map[string]json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		for i := range list {
+			list[i] = pickFields(list[i], fields)
+		}
+		return list
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) == nil {
+		return pickFields(object, fields)
+	}
+	return v
+}
+
+func pickFields(object map[string]json.RawMessage, fields repository.Fields) map[string]json.RawMessage {
+	picked := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := object[field]; ok {
+			picked[field] = value
+		}
+	}
+	return picked
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"golang-assessment/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type HealthController struct {
+	service *services.HealthService
+	log     *logrus.Logger
+}
+
+func NewHealthController(service *services.HealthService, log *logrus.Logger) *HealthController {
+	return &HealthController{service: service, log: log}
+}
+
+// Liveness only reports that the process is up and serving HTTP.
+func (ctrl *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": services.StatusOK})
+}
+
+// Readiness fails while draining or when any dependency check fails.
+func (ctrl *HealthController) Readiness(c *gin.Context) {
+	if ctrl.service.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": services.StatusDraining})
+		return
+	}
+	report := ctrl.service.Check(c.Request.Context())
+	if report.Status != services.StatusOK {
+		ctrl.logFailures(c, "Readiness", report)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": services.StatusOK})
+}
+
+// Health reports every dependency with its status and latency.
+func (ctrl *HealthController) Health(c *gin.Context) {
+	report := ctrl.service.Check(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != services.StatusOK {
+		ctrl.logFailures(c, "Health", report)
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// logFailures logs why each failed check failed, which the response leaves out.
+func (ctrl *HealthController) logFailures(c *gin.Context, endpoint string, report services.HealthReport) {
+	for _, result := range report.Checks {
+		if result.Status != services.StatusOK {
+			ctrl.log.WithContext(c.Request.Context()).WithField("check", result.Name).Warnf("%s check failed: %s", endpoint, result.Error)
+		}
+	}
+}
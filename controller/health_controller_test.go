@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	loggerNew "golang-assessment/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-assessment/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHealthRouter(service *services.HealthService) *gin.Engine {
+	controller := NewHealthController(service, loggerNew.Discard())
+	router := gin.New()
+	router.GET("/healthz", controller.Liveness)
+	router.GET("/readyz", controller.Readiness)
+	router.GET("/health", controller.Health)
+	return router
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	// Setup
+	dbErr := error(nil)
+	service := services.NewHealthService(time.Second)
+	service.Register("database", func(ctx context.Context) error { return dbErr })
+	router := setupHealthRouter(service)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("TestHealthy", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/healthz").Code)
+		assert.Equal(t, http.StatusOK, serve("/readyz").Code)
+
+		rr := serve("/health")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var report services.HealthReport
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, services.StatusOK, report.Status)
+		assert.Equal(t, "database", report.Checks[0].Name)
+	})
+
+	t.Run("TestDatabaseDown", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+		defer func() { dbErr = nil }()
+
+		// The process is still alive, but not ready
+		assert.Equal(t, http.StatusOK, serve("/healthz").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, serve("/readyz").Code)
+
+		rr := serve("/health")
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		var report services.HealthReport
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, services.StatusUnavailable, report.Checks[0].Status)
+
+		// The cause is logged, not sent to unauthenticated clients
+		assert.NotContains(t, rr.Body.String(), "connection refused")
+		assert.NotContains(t, serve("/readyz").Body.String(), "connection refused")
+	})
+
+	t.Run("TestDraining", func(t *testing.T) {
+		service.SetDraining()
+		assert.Equal(t, http.StatusOK, serve("/healthz").Code)
+
+		rr := serve("/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assertResponseBody(t, rr.Body.Bytes(), gin.H{"status": services.StatusDraining})
+	})
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"golang-assessment/auth"
+	"golang-assessment/problem"
+	"golang-assessment/redact"
+	repository "golang-assessment/respository"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseFields reads the ?fields= sparse fieldset. Without it every field is
+// returned.
+func parseFields(c *gin.Context) (repository.Fields, *problem.Problem) {
+	raw, ok := c.GetQuery("fields")
+	if !ok {
+		return nil, nil
+	}
+	fields, err := repository.ParseFields(raw)
+	if err != nil {
+		return nil, problem.InvalidQuery("fields", err.Error())
+	}
+	if len(fields) == 0 {
+		return nil, problem.InvalidQuery("fields", "must name at least one field")
+	}
+	return fields, nil
+}
+
+// parseListQuery reads the filter, sort and fields parameters of a list
+// request. Filtering or sorting by salary would reveal salaries, so it is
+// forbidden to roles the policy hides them from.
+func parseListQuery(c *gin.Context, policy *redact.Policy) (repository.ListQuery, *problem.Problem) {
+	var query repository.ListQuery
+	query.Filter.Position = c.Query("position")
+	query.Filter.PositionPrefix = c.Query("position_prefix")
+	query.Filter.NameContains = c.Query("name_contains")
+
+	for _, bound := range []struct {
+		param  string
+		target **float64
+	}{
+		{"salary_gte", &query.Filter.SalaryGTE},
+		{"salary_lte", &query.Filter.SalaryLTE},
+	} {
+		raw, ok := c.GetQuery(bound.param)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return query, problem.InvalidQuery(bound.param, "must be a number")
+		}
+		*bound.target = &value
+	}
+	if query.Filter.SalaryGTE != nil && query.Filter.SalaryLTE != nil && *query.Filter.SalaryLTE < *query.Filter.SalaryGTE {
+		return query, problem.InvalidQuery("salary_lte", "must not be below salary_gte")
+	}
+
+	if raw, ok := c.GetQuery("sort"); ok {
+		keys, err := repository.ParseSort(raw)
+		if err != nil {
+			return query, problem.InvalidQuery("sort", err.Error())
+		}
+		query.Sort = keys
+	}
+
+	fields, paramErr := parseFields(c)
+	if paramErr != nil {
+		return query, paramErr
+	}
+	query.Fields = fields
+
+	if policy.Hides(auth.RoleFromContext(c.Request.Context()), redact.ClassFinancial) {
+		if param := salaryParam(c, query); param != "" {
+			return query, problem.New(http.StatusForbidden, problem.CodeForbidden, param+" is only available to roles that may see salaries")
+		}
+	}
+	return query, nil
+}
+
+// salaryParam names the first parameter that filters or sorts by salary.
+func salaryParam(c *gin.Context, query repository.ListQuery) string {
+	for _, param := range []string{"salary_gte", "salary_lte"} {
+		if _, ok := c.GetQuery(param); ok {
+			return param
+		}
+	}
+	for _, key := range query.Sort {
+		if key.Field == "salary" {
+			return "sort=salary"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"fmt"
+	"golang-assessment/services"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listResponse is the envelope around every paginated list.
+type listResponse struct {
+	Data       interface{} `json:"data"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	Total      int64       `json:"total"`
+	TotalPages int         `json:"total_pages"`
+	HasNext    bool        `json:"has_next"`
+	Links      pageLinks   `json:"links"`
+}
+
+// pageLinks are relative URLs that keep every query parameter of the request
+// except page. Next and Prev are left out on the last and first page.
+type pageLinks struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+func newListResponse(c *gin.Context, page services.EmployeePage, data interface{}) listResponse {
+	last := page.TotalPages()
+	if last < 1 {
+		last = 1
+	}
+	links := pageLinks{
+		First: pageURL(c.Request.URL, 1),
+		Last:  pageURL(c.Request.URL, last),
+	}
+	if page.HasNext() {
+		links.Next = pageURL(c.Request.URL, page.Page+1)
+	}
+	if page.Page > 1 {
+		// From past the end, step back to the last page rather than into
+		// another empty one
+		prev := page.Page - 1
+		if prev > last {
+			prev = last
+		}
+		links.Prev = pageURL(c.Request.URL, prev)
+	}
+
+	return listResponse{
+		Data:       data,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Total:      page.Total,
+		TotalPages: page.TotalPages(),
+		HasNext:    page.HasNext(),
+		Links:      links,
+	}
+}
+
+// cursorListResponse is the envelope for cursor pagination. It has no total:
+// counting defeats the point of walking a large table by key.
+type cursorListResponse struct {
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit"`
+	HasNext    bool        `json:"has_next"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Links      cursorLinks `json:"links"`
+}
+
+type cursorLinks struct {
+	First string `json:"first"`
+	Next  string `json:"next,omitempty"`
+}
+
+// newCursorListResponse serves every cursor-paged list; next is empty on the
+// last page.
+func newCursorListResponse(c *gin.Context, limit int, next string, data interface{}) cursorListResponse {
+	links := cursorLinks{First: cursorURL(c.Request.URL, "")}
+	if next != "" {
+		links.Next = cursorURL(c.Request.URL, next)
+	}
+	return cursorListResponse{
+		Data:       data,
+		Limit:      limit,
+		HasNext:    next != "",
+		NextCursor: next,
+		Links:      links,
+	}
+}
+
+func pageURL(requestURL *url.URL, page int) string {
+	query := requestURL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return requestURL.Path + "?" + query.Encode()
+}
+
+func cursorURL(requestURL *url.URL, cursor string) string {
+	query := requestURL.Query()
+	query.Set("cursor", cursor)
+	return requestURL.Path + "?" + query.Encode()
+}
+
+func (l pageLinks) header() string {
+	return linkHeader([][2]string{{"first", l.First}, {"prev", l.Prev}, {"next", l.Next}, {"last", l.Last}})
+}
+
+func (l cursorLinks) header() string {
+	return linkHeader([][2]string{{"first", l.First}, {"next", l.Next}})
+}
+
+// linkHeader formats rel/URL pairs as an RFC 8288 Link header, skipping
+// empty URLs.
+func linkHeader(links [][2]string) string {
+	var parts []string
+	for _, link := range links {
+		if link[1] != "" {
+			parts = append(parts, fmt.Sprintf(`<%s>; rel="%s"`, link[1], link[0]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
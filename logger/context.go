@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHook stamps entries logged with WithContext(ctx) with the request ID
+// and the current trace and span IDs, so a log line can be matched to both
+// the request and its trace.
+type contextHook struct{}
+
+func (contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (contextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if requestID := RequestIDFromContext(entry.Context); requestID != "" {
+		entry.Data["request_id"] = requestID
+	}
+	if spanContext := trace.SpanContextFromContext(entry.Context); spanContext.IsValid() {
+		entry.Data["trace_id"] = spanContext.TraceID().String()
+		entry.Data["span_id"] = spanContext.SpanID().String()
+	}
+	return nil
+}
@@ -1,26 +1,83 @@
 package logger
 
 import (
+	"fmt"
+	"golang-assessment/config"
+	"golang-assessment/redact"
+	"io"
 	"os"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Log *logrus.Logger
-
-func InitLogger() {
+// New builds a logger from the logging config. The returned close function
+// flushes and closes a log file; it is a no-op for stdout and stderr.
+func New(cfg config.LoggingConfig) (*logrus.Logger, func() error, error) {
 	log := logrus.New()
-	log.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
-	})
-	file, err := os.OpenFile("golang-assessment", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+
+	level, err := logrus.ParseLevel(cfg.Level)
 	if err != nil {
-		log.SetOutput(file)
-	} else {
+		return nil, nil, err
+	}
+	log.SetLevel(level)
+
+	switch cfg.Format {
+	case config.LogFormatJSON:
+		log.SetFormatter(redactingFormatter{&logrus.JSONFormatter{}})
+	case config.LogFormatText, "":
+		log.SetFormatter(redactingFormatter{&logrus.TextFormatter{
+			FullTimestamp: true,
+		}})
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	closer := func() error { return nil }
+	switch cfg.Output {
+	case config.LogOutputStdout, "":
 		log.SetOutput(os.Stdout)
+	case config.LogOutputStderr:
+		log.SetOutput(os.Stderr)
+	default:
+		file := &lumberjack.Logger{
+			Filename:   cfg.Output,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+		log.SetOutput(file)
+		closer = file.Close
 	}
 
-	log.SetLevel(logrus.DebugLevel)
-	Log = log
+	log.AddHook(contextHook{})
+	return log, closer, nil
+}
+
+// redactingFormatter masks sensitive struct fields (see package redact) in
+// entry fields before the wrapped formatter writes them.
+type redactingFormatter struct {
+	logrus.Formatter
+}
+
+func (f redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if len(entry.Data) == 0 {
+		return f.Formatter.Format(entry)
+	}
+	redacted := *entry
+	redacted.Data = make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		redacted.Data[key] = redact.Value(value)
+	}
+	return f.Formatter.Format(&redacted)
+}
 
+// Discard returns a logger that drops everything, for tests and callers
+// that do not care about logs.
+func Discard() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	log.AddHook(contextHook{})
+	return log
 }
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang-assessment/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func newJSONLogger(t *testing.T) (*logrus.Logger, *bytes.Buffer) {
+	cfg := config.DefaultConfig().Logging
+	cfg.Format = config.LogFormatJSON
+	log, _, err := New(cfg)
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	return log, buf
+}
+
+func decodeEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	return entry
+}
+
+func TestNew(t *testing.T) {
+	t.Run("JSON format", func(t *testing.T) {
+		log, buf := newJSONLogger(t)
+
+		log.WithField("employee_id", 7).Info("Employee created")
+
+		entry := decodeEntry(t, buf)
+		assert.Equal(t, "Employee created", entry["msg"])
+		assert.Equal(t, "info", entry["level"])
+		assert.Equal(t, float64(7), entry["employee_id"])
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		cfg := config.DefaultConfig().Logging
+		cfg.Level = "loud"
+		_, _, err := New(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("file output", func(t *testing.T) {
+		cfg := config.DefaultConfig().Logging
+		cfg.Output = filepath.Join(t.TempDir(), "app.log")
+		log, closeLog, err := New(cfg)
+		assert.NoError(t, err)
+
+		log.Info("written to file")
+		assert.NoError(t, closeLog())
+		assert.FileExists(t, cfg.Output)
+	})
+}
+
+func TestContextHook(t *testing.T) {
+	t.Run("request and trace IDs", func(t *testing.T) {
+		log, buf := newJSONLogger(t)
+		ctx := WithRequestID(context.Background(), "req-123")
+		ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(ctx, "operation")
+		defer span.End()
+
+		log.WithContext(ctx).Info("hello")
+
+		entry := decodeEntry(t, buf)
+		assert.Equal(t, "req-123", entry["request_id"])
+		assert.Equal(t, span.SpanContext().TraceID().String(), entry["trace_id"])
+		assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+	})
+
+	t.Run("no context", func(t *testing.T) {
+		log, buf := newJSONLogger(t)
+
+		log.Info("hello")
+
+		entry := decodeEntry(t, buf)
+		assert.NotContains(t, entry, "request_id")
+		assert.NotContains(t, entry, "trace_id")
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, RequestIDFromContext(c.Request.Context()))
+	})
+
+	t.Run("echoes the caller's ID", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "abc-123", w.Body.String())
+	})
+
+	t.Run("generates an ID", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		requestID := w.Header().Get(RequestIDHeader)
+		assert.NotEmpty(t, requestID)
+		assert.Equal(t, requestID, w.Body.String())
+	})
+
+	t.Run("replaces an oversized ID", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(RequestIDHeader, strings.Repeat("x", maxRequestIDLength+1))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Len(t, w.Header().Get(RequestIDHeader), 36)
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	log, buf := newJSONLogger(t)
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(log))
+	router.GET("/employees/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/employees/42", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	entry := decodeEntry(t, buf)
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "abc-123", entry["request_id"])
+	assert.Equal(t, "/employees/:id", entry["route"])
+	assert.Equal(t, "/employees/42", entry["path"])
+	assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+}
+
+func TestRedactingFormatter(t *testing.T) {
+	type employee struct {
+		ID     int     `json:"id"`
+		Name   string  `json:"name" sensitive:"pii"`
+		Salary float64 `json:"salary" sensitive:"financial"`
+	}
+	log, buf := newJSONLogger(t)
+
+	log.WithField("employee", employee{ID: 1, Name: "John Doe", Salary: 60000}).Info("Employee created")
+
+	entry := decodeEntry(t, buf)
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "[REDACTED]", "salary": "[REDACTED]"}, entry["employee"])
+	assert.NotContains(t, buf.String(), "John Doe")
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength stops clients from stuffing arbitrary data into
+	// every log line through the header.
+	maxRequestIDLength = 128
+)
+
+// RequestID reuses the caller's X-Request-ID (or generates one), echoes it in
+// the response and puts it in the request context for the log hook.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// AccessLog writes one line per request with the route, status and latency.
+func AccessLog(log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"route":      c.FullPath(),
+			"path":       c.Request.URL.Path,
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip":  c.ClientIP(),
+		})
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			entry.Error("Request completed")
+		case status >= 400:
+			entry.Warn("Request completed")
+		default:
+			entry.Info("Request completed")
+		}
+	}
+}
@@ -1,15 +1,142 @@
 package main
 
 import (
+	"context"
 	"golang-assessment/config"
 	"golang-assessment/logger"
+	"golang-assessment/metrics"
+	"golang-assessment/migrations"
+	"golang-assessment/pagination"
+	repository "golang-assessment/respository"
 	"golang-assessment/routers"
+	"golang-assessment/server"
+	"golang-assessment/services"
+	"golang-assessment/tracing"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gorm.io/gorm"
 )
 
 func main() {
-	logger.InitLogger()
-	db := config.SetupDatabase()
-	router := routers.SetupRouter(db)
-	logger.Log.Info("Starting the server on port 8080")
-	router.Run(":8080")
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	appLog, closeLog, err := logger.New(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Error setting up logging: %v", err)
+	}
+	appLog.Debugf("Loaded config: %+v", cfg.Redacted())
+
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(&cfg.Database, appLog, args[1:]); err != nil {
+			appLog.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if len(args) > 0 {
+		appLog.Fatalf("Unknown command %q", args[0])
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		appLog.Fatalf("Error setting up tracing: %v", err)
+	}
+
+	healthService := services.NewHealthService(cfg.Server.HealthCheckTimeout)
+	appMetrics := metrics.New()
+	var db *gorm.DB
+	if cfg.Database.Dialect != config.DialectMemory {
+		db, err = config.OpenDatabase(&cfg.Database)
+		if err != nil {
+			appLog.Fatalf("Error connecting to database: %v", err)
+		}
+		appLog.Info("Database connection established")
+		if err := db.Use(tracing.GormPlugin{}); err != nil {
+			appLog.Fatalf("Error installing the tracing plugin: %v", err)
+		}
+		migrator, err := migrations.NewMigrator(db, cfg.Database.Dialect, appLog)
+		if err != nil {
+			appLog.Fatalf("Error loading migrations: %v", err)
+		}
+		// Refuse to serve against a schema this binary does not understand
+		if err := migrator.EnsureCurrent(); err != nil {
+			appLog.Fatalf("Database schema check failed: %v", err)
+		}
+
+		healthService.Register("database", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		})
+		// EnsureCurrent only reads schema_migrations, so probes never run DDL
+		healthService.Register("migrations", func(ctx context.Context) error {
+			return migrator.EnsureCurrent()
+		})
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			appLog.Fatalf("Error getting the connection pool: %v", err)
+		}
+		appMetrics.RegisterDB(sqlDB, cfg.Database.DBName)
+	}
+	store := repository.NewEmployeeStore(cfg.Database.Dialect, db, appLog)
+	store = metrics.InstrumentStore(tracing.TraceStore(store), appMetrics)
+	appMetrics.RegisterEmployeeCount(store.CountEmployees)
+	var cursors *pagination.CursorCodec
+	if cfg.Pagination.CursorSecret != "" {
+		cursors = pagination.NewCursorCodec([]byte(cfg.Pagination.CursorSecret))
+	} else {
+		appLog.Warn("pagination.cursor_secret is not set; list cursors will not survive a restart or work across replicas")
+		if cursors, err = pagination.RandomCursorCodec(); err != nil {
+			appLog.Fatalf("Error generating a cursor key: %v", err)
+		}
+	}
+	router := routers.SetupRouter(cfg, store, cursors, healthService, appMetrics, appLog)
+
+	srv := server.New(cfg.Server, router, appLog)
+	// Hooks run in reverse order: the log file closes last, after tracing has
+	// exported the shutdown spans.
+	srv.OnShutdown("logging", func(context.Context) error { return closeLog() })
+	srv.OnShutdown("tracing", shutdownTracing)
+	srv.OnDrain(healthService.SetDraining)
+	if db != nil {
+		srv.OnShutdown("database", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		})
+	}
+
+	if cfg.SoftDelete.Retention > 0 {
+		purgeCtx, stopPurge := context.WithCancel(context.Background())
+		purgeDone := make(chan struct{})
+		go func() {
+			defer close(purgeDone)
+			services.NewPurger(store, cfg.SoftDelete, appLog).Run(purgeCtx)
+		}()
+		// Registered after the database hook, so it stops before the pool closes
+		srv.OnShutdown("purge", func(ctx context.Context) error {
+			stopPurge()
+			select {
+			case <-purgeDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := srv.Run(ctx); err != nil {
+		appLog.Fatalf("Server error: %v", err)
+	}
 }
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "employee"
+	// countTimeout keeps a slow COUNT(*) from stalling the whole scrape.
+	countTimeout = 5 * time.Second
+)
+
+// Metrics owns a Prometheus registry with the HTTP, repository and database
+// collectors. Each instance has its own registry, so tests do not share state.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	storeDuration *prometheus.HistogramVec
+	storeErrors   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		storeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_operation_duration_seconds",
+			Help:      "Employee store latency by operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		storeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "store_operation_errors_total",
+			Help:      "Employee store operations that returned an error, by operation.",
+		}, []string{"operation"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.storeDuration,
+		m.storeErrors,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDB exports the sql.DBStats of the connection pool.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterEmployeeCount exports the number of stored employees, read from
+// count on every scrape.
+func (m *Metrics) RegisterEmployeeCount(count func(ctx context.Context) (int64, error)) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "employees",
+		Help:      "Number of stored employees.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+		defer cancel()
+		n, err := count(ctx)
+		if err != nil {
+			// Prometheus treats NaN as "no value", which is better than a
+			// misleading zero.
+			return math.NaN()
+		}
+		return float64(n)
+	}))
+}
+
+// Middleware records request counts and latency. Routes are labelled with
+// the gin route template (e.g. /employees/:id), never the raw path, to keep
+// label cardinality bounded.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// observe records one store call. Pass the error the call returned, if any.
+func (m *Metrics) observe(operation string, start time.Time, err error) {
+	m.storeDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.storeErrors.WithLabelValues(operation).Inc()
+	}
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-assessment/config"
+	loggerNew "golang-assessment/logger"
+	"golang-assessment/models"
+	repository "golang-assessment/respository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	m.Handler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	return rr.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	// Setup
+	m := New()
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/employees/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/employees/1", "/employees/2", "/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Both IDs share the route template label
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/employees/:id", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestInstrumentStore(t *testing.T) {
+	// Setup
+	m := New()
+	store := InstrumentStore(repository.NewMemoryEmployeeStore(loggerNew.Discard()), m)
+
+	store.CreateEmployee(context.Background(), &models.Employee{Name: "John Doe"})
+	_, err := store.GetEmployeeByID(context.Background(), 1, nil)
+	assert.Nil(t, err)
+	_, err = store.GetEmployeeByID(context.Background(), 100, nil)
+	assert.NotNil(t, err)
+	assert.NotNil(t, store.DeleteEmployee(context.Background(), 100, 1, "admin"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.storeErrors.WithLabelValues("get")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.storeErrors.WithLabelValues("delete")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.storeErrors.WithLabelValues("create")))
+	assert.Contains(t, scrape(t, m), `employee_store_operation_duration_seconds_count{operation="get"} 2`)
+}
+
+func TestRegisterEmployeeCount(t *testing.T) {
+	m := New()
+	store := repository.NewMemoryEmployeeStore(loggerNew.Discard())
+	m.RegisterEmployeeCount(store.CountEmployees)
+
+	store.CreateEmployee(context.Background(), &models.Employee{Name: "John Doe"})
+	store.CreateEmployee(context.Background(), &models.Employee{Name: "Jane Doe"})
+	assert.Contains(t, scrape(t, m), "employee_employees 2")
+
+	failing := New()
+	failing.RegisterEmployeeCount(func(ctx context.Context) (int64, error) { return 0, errors.New("database down") })
+	assert.Contains(t, scrape(t, failing), "employee_employees NaN")
+}
+
+func TestRegisterDB(t *testing.T) {
+	m := New()
+	db, err := config.OpenDatabase(&config.DatabaseConfig{Dialect: config.DialectSQLite, Path: config.SQLiteInMemory})
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+	m.RegisterDB(sqlDB, "employees")
+
+	body := scrape(t, m)
+	assert.True(t, strings.Contains(body, `go_sql_open_connections{db_name="employees"}`))
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"golang-assessment/models"
+	repository "golang-assessment/respository"
+	"time"
+)
+
+// instrumentedStore decorates an EmployeeStore with latency and error metrics.
+type instrumentedStore struct {
+	next    repository.EmployeeStore
+	metrics *Metrics
+}
+
+func InstrumentStore(store repository.EmployeeStore, m *Metrics) repository.EmployeeStore {
+	return &instrumentedStore{next: store, metrics: m}
+}
+
+// WithTx times the whole transaction and instruments the calls made in it.
+func (s *instrumentedStore) WithTx(ctx context.Context, fn func(store repository.EmployeeStore) error) (err error) {
+	defer func(start time.Time) { s.metrics.observe("transaction", start, err) }(time.Now())
+	return s.next.WithTx(ctx, func(tx repository.EmployeeStore) error {
+		return fn(InstrumentStore(tx, s.metrics))
+	})
+}
+
+func (s *instrumentedStore) CreateEmployee(ctx context.Context, employee *models.Employee) (err error) {
+	defer func(start time.Time) { s.metrics.observe("create", start, err) }(time.Now())
+	return s.next.CreateEmployee(ctx, employee)
+}
+
+func (s *instrumentedStore) GetEmployeeByID(ctx context.Context, id int, fields repository.Fields) (employee models.Employee, err error) {
+	defer func(start time.Time) { s.metrics.observe("get", start, err) }(time.Now())
+	return s.next.GetEmployeeByID(ctx, id, fields)
+}
+
+func (s *instrumentedStore) UpdateEmployee(ctx context.Context, employee *models.Employee) (err error) {
+	defer func(start time.Time) { s.metrics.observe("update", start, err) }(time.Now())
+	return s.next.UpdateEmployee(ctx, employee)
+}
+
+func (s *instrumentedStore) PatchEmployee(ctx context.Context, id, version int, changes repository.EmployeeChanges) (newVersion int, err error) {
+	defer func(start time.Time) { s.metrics.observe("patch", start, err) }(time.Now())
+	return s.next.PatchEmployee(ctx, id, version, changes)
+}
+
+func (s *instrumentedStore) DeleteEmployee(ctx context.Context, id, version int, actor string) (err error) {
+	defer func(start time.Time) { s.metrics.observe("delete", start, err) }(time.Now())
+	return s.next.DeleteEmployee(ctx, id, version, actor)
+}
+
+func (s *instrumentedStore) RestoreEmployee(ctx context.Context, id, version int, actor string) (newVersion int, err error) {
+	defer func(start time.Time) { s.metrics.observe("restore", start, err) }(time.Now())
+	return s.next.RestoreEmployee(ctx, id, version, actor)
+}
+
+func (s *instrumentedStore) PurgeEmployees(ctx context.Context, deletedBefore time.Time) (purged int64, err error) {
+	defer func(start time.Time) { s.metrics.observe("purge", start, err) }(time.Now())
+	return s.next.PurgeEmployees(ctx, deletedBefore)
+}
+
+func (s *instrumentedStore) ListEmployee(ctx context.Context, query repository.ListQuery, offset, limit int) (employees []models.Employee, total int64, err error) {
+	defer func(start time.Time) { s.metrics.observe("list", start, err) }(time.Now())
+	return s.next.ListEmployee(ctx, query, offset, limit)
+}
+
+func (s *instrumentedStore) ListEmployeesAfter(ctx context.Context, query repository.ListQuery, after *models.Employee, limit int) (employees []models.Employee, err error) {
+	defer func(start time.Time) { s.metrics.observe("list_after", start, err) }(time.Now())
+	return s.next.ListEmployeesAfter(ctx, query, after, limit)
+}
+
+func (s *instrumentedStore) CountEmployees(ctx context.Context) (count int64, err error) {
+	defer func(start time.Time) { s.metrics.observe("count", start, err) }(time.Now())
+	return s.next.CountEmployees(ctx)
+}
+
+func (s *instrumentedStore) AppendAudit(ctx context.Context, entry *models.AuditEntry) (err error) {
+	defer func(start time.Time) { s.metrics.observe("audit_append", start, err) }(time.Now())
+	return s.next.AppendAudit(ctx, entry)
+}
+
+func (s *instrumentedStore) ListAudit(ctx context.Context, query repository.AuditQuery, after int64, limit int) (entries []models.AuditEntry, err error) {
+	defer func(start time.Time) { s.metrics.observe("audit_list", start, err) }(time.Now())
+	return s.next.ListAudit(ctx, query, after, limit)
+}
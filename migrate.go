@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"golang-assessment/config"
+	"golang-assessment/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+)
+
+const migrateUsage = "usage: golang-assessment migrate up|down|status|to N"
+
+// runMigrate implements the "migrate" subcommand.
+func runMigrate(dbConfig *config.DatabaseConfig, log *logrus.Logger, args []string) error {
+	if dbConfig.Dialect == config.DialectMemory {
+		return fmt.Errorf("the %q dialect has no schema to migrate", config.DialectMemory)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := config.OpenDatabase(dbConfig)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.NewMigrator(db, dbConfig.Dialect, log)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return migrator.Up()
+	case args[0] == "down" && len(args) == 1:
+		return migrator.Down()
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+		return migrator.To(version)
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
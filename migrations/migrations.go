@@ -0,0 +1,403 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"golang-assessment/config"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Migrations live in sql/<dialect>/NNNN_name.up.sql with a matching
+// NNNN_name.down.sql. Statements inside a file are separated by a semicolon
+// at the end of a line.
+//
+//go:embed sql
+var files embed.FS
+
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+const (
+	// lockName is used as the MySQL GET_LOCK name; lockKey is the Postgres
+	// advisory lock key. Both only have to be unique to this application.
+	lockName    = "golang-assessment.schema_migrations"
+	lockKey     = int64(0x656d706c6f796565)
+	lockTimeout = 60 * time.Second
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+	log        *logrus.Logger
+}
+
+func NewMigrator(db *gorm.DB, dialect string, log *logrus.Logger) (*Migrator, error) {
+	if dialect == "" {
+		dialect = config.DialectPostgres
+	}
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, log: log}, nil
+}
+
+// Load returns the embedded migrations for a dialect, ordered by version.
+func Load(dialect string) ([]Migration, error) {
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", path.Join(dir, entry.Name()))
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Version == 0 {
+			return nil, fmt.Errorf("migration %s: version 0 is reserved for the empty schema", migration.Name)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest is the version the schema reaches once every migration is applied.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down reverts the most recently applied migration.
+func (m *Migrator) Down() error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	current := currentVersion(applied)
+	if current == 0 {
+		m.log.Info("No migrations to revert")
+		return nil
+	}
+	target := 0
+	for version := range applied {
+		if version < current && version > target {
+			target = version
+		}
+	}
+	return m.To(target)
+}
+
+// To migrates up or down until exactly the migrations numbered at or below
+// version are applied. Version 0 reverts everything.
+func (m *Migrator) To(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > version {
+			if err := m.apply(migration, false); err != nil {
+				return err
+			}
+		}
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+			if err := m.apply(migration, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	return m.statuses(applied), nil
+}
+
+// statuses lists every migration known to this binary or recorded in applied.
+func (m *Migrator) statuses(applied map[int]schemaMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	// Versions applied by a newer binary are still reported
+	for version, record := range applied {
+		if m.find(version) == nil {
+			statuses = append(statuses, MigrationStatus{Version: version, Name: record.Name, Applied: true, AppliedAt: &record.AppliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// EnsureCurrent returns ErrSchemaBehind unless every migration known to this
+// binary has been applied. It never changes the schema, so it is safe to run
+// from health checks against a database the application may only read.
+func (m *Migrator) EnsureCurrent() error {
+	applied, err := m.appliedIfExists()
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, status := range m.statuses(applied) {
+		if !status.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s, run \"migrate up\"", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    applied_at timestamp NOT NULL
+)`).Error
+}
+
+func (m *Migrator) applied() (map[int]schemaMigration, error) {
+	var records []schemaMigration
+	if err := m.db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// appliedIfExists is applied without creating schema_migrations; a database
+// that has never been migrated is at version 0.
+func (m *Migrator) appliedIfExists() (map[int]schemaMigration, error) {
+	if !m.db.Migrator().HasTable(&schemaMigration{}) {
+		return map[int]schemaMigration{}, nil
+	}
+	return m.applied()
+}
+
+func currentVersion(applied map[int]schemaMigration) int {
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current
+}
+
+func (m *Migrator) apply(migration Migration, up bool) error {
+	script, direction := migration.Up, "up"
+	if !up {
+		script, direction = migration.Down, "down"
+	}
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		// Re-check inside the transaction in case another process got here
+		// first (SQLite has no advisory lock to rely on).
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if (count > 0) == up {
+			return nil
+		}
+
+		for _, statement := range statements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		if up {
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+		}
+		return tx.Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	m.log.Infof("Migrated %s: %d_%s", direction, migration.Version, migration.Name)
+	return nil
+}
+
+func statements(script string) []string {
+	var result []string
+	for _, statement := range strings.Split(script, ";\n") {
+		statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+		if statement != "" {
+			result = append(result, statement)
+		}
+	}
+	return result
+}
+
+// lock takes a cluster-wide lock so two replicas never migrate at once. The
+// lock is bound to a database session, so it holds on to one connection from
+// the pool until unlock is called.
+func (m *Migrator) lock() (func(), error) {
+	var query, release string
+	var args []interface{}
+	switch m.dialect {
+	case config.DialectPostgres:
+		query, release = "SELECT pg_advisory_lock($1)", "SELECT pg_advisory_unlock($1)"
+		args = []interface{}{lockKey}
+	case config.DialectMySQL:
+		query, release = "SELECT GET_LOCK(?, ?)", "SELECT RELEASE_LOCK(?)"
+		args = []interface{}{lockName, int(lockTimeout.Seconds())}
+	default:
+		// SQLite only allows one writer at a time and apply re-checks the
+		// version inside its transaction.
+		return func() {}, nil
+	}
+
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout+5*time.Second)
+	defer cancel()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.dialect == config.DialectPostgres {
+		_, err = conn.ExecContext(ctx, query, args...)
+	} else {
+		// GET_LOCK returns 1 once acquired and 0 if it timed out
+		var acquired sql.NullInt64
+		err = conn.QueryRowContext(ctx, query, args...).Scan(&acquired)
+		if err == nil && acquired.Int64 != 1 {
+			err = fmt.Errorf("timed out after %s", lockTimeout)
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("acquiring migration lock: %w", err)
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), release, args[0]); err != nil {
+			m.log.Errorf("Error releasing migration lock: %v", err)
+		}
+		conn.Close()
+	}, nil
+}
@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+
+	"golang-assessment/config"
+	loggerNew "golang-assessment/logger"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := config.OpenDatabase(&config.DatabaseConfig{Dialect: config.DialectSQLite, Path: config.SQLiteInMemory})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{config.DialectPostgres, config.DialectMySQL, config.DialectSQLite} {
+		migrations, err := Load(dialect)
+		assert.Nil(t, err, dialect)
+		assert.NotEmpty(t, migrations, dialect)
+		assert.Equal(t, 1, migrations[0].Version, dialect)
+	}
+
+	// Every dialect has to ship the same set of migrations
+	postgres, _ := Load(config.DialectPostgres)
+	mysql, _ := Load(config.DialectMySQL)
+	sqlite, _ := Load(config.DialectSQLite)
+	assert.Equal(t, len(postgres), len(mysql))
+	assert.Equal(t, len(postgres), len(sqlite))
+
+	_, err := Load("oracle")
+	assert.NotNil(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	migrator, err := NewMigrator(db, config.DialectSQLite, loggerNew.Discard())
+	assert.Nil(t, err)
+
+	t.Run("TestEnsureCurrent_Behind", func(t *testing.T) {
+		err := migrator.EnsureCurrent()
+		assert.True(t, errors.Is(err, ErrSchemaBehind))
+
+		// Checking is read-only, the table is only created by migrating
+		assert.False(t, db.Migrator().HasTable("schema_migrations"))
+	})
+
+	t.Run("TestUp", func(t *testing.T) {
+		assert.Nil(t, migrator.Up())
+		assert.True(t, db.Migrator().HasTable("employees"))
+		assert.Nil(t, migrator.EnsureCurrent())
+
+		// Running up again is a no-op
+		assert.Nil(t, migrator.Up())
+	})
+
+	t.Run("TestStatus", func(t *testing.T) {
+		statuses, err := migrator.Status()
+		assert.Nil(t, err)
+		assert.Len(t, statuses, len(migrator.migrations))
+		for _, status := range statuses {
+			assert.True(t, status.Applied)
+			assert.NotNil(t, status.AppliedAt)
+		}
+	})
+
+	t.Run("TestDown", func(t *testing.T) {
+		for range migrator.migrations {
+			assert.Nil(t, migrator.Down())
+		}
+		assert.False(t, db.Migrator().HasTable("employees"))
+		assert.True(t, errors.Is(migrator.EnsureCurrent(), ErrSchemaBehind))
+
+		// Nothing left to revert
+		assert.Nil(t, migrator.Down())
+	})
+
+	t.Run("TestTo", func(t *testing.T) {
+		assert.Nil(t, migrator.To(1))
+		assert.True(t, db.Migrator().HasTable("employees"))
+
+		assert.Nil(t, migrator.To(0))
+		assert.False(t, db.Migrator().HasTable("employees"))
+
+		assert.NotNil(t, migrator.To(9999))
+	})
+}
+
+func TestStatements(t *testing.T) {
+	script := "CREATE TABLE a (id int);\nCREATE INDEX idx ON a (id);\n\n"
+	assert.Equal(t, []string{"CREATE TABLE a (id int)", "CREATE INDEX idx ON a (id)"}, statements(script))
+}
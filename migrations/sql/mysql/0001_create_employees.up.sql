@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS employees (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    name longtext,
+    position longtext,
+    salary double
+);
@@ -0,0 +1,7 @@
+DROP INDEX idx_employees_salary ON employees;
+DROP INDEX idx_employees_position ON employees;
+DROP INDEX idx_employees_name ON employees;
+ALTER TABLE employees
+    MODIFY name longtext NULL,
+    MODIFY position longtext NULL,
+    MODIFY salary double NULL;
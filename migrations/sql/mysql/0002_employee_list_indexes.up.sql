@@ -0,0 +1,13 @@
+-- Keyset pagination compares sort columns, which only works without NULLs
+UPDATE employees SET name = '' WHERE name IS NULL;
+UPDATE employees SET position = '' WHERE position IS NULL;
+UPDATE employees SET salary = 0 WHERE salary IS NULL;
+-- longtext cannot be indexed
+ALTER TABLE employees
+    MODIFY name varchar(255) NOT NULL,
+    MODIFY position varchar(255) NOT NULL,
+    MODIFY salary double NOT NULL;
+-- One index per sortable field, with id as the tie-breaker every sort ends on
+CREATE INDEX idx_employees_name ON employees (name, id);
+CREATE INDEX idx_employees_position ON employees (position, id);
+CREATE INDEX idx_employees_salary ON employees (salary, id);
@@ -0,0 +1,3 @@
+-- Rows that existed before this migration get its time, not their real creation time
+ALTER TABLE employees ADD COLUMN created_at datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
+ALTER TABLE employees ADD COLUMN updated_at datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
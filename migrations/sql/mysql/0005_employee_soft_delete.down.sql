@@ -0,0 +1,7 @@
+-- Soft-deleted rows would become visible again, so they go for good
+DELETE FROM employees WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_employees_deleted_at ON employees;
+ALTER TABLE employees DROP COLUMN deleted_at;
+ALTER TABLE employees DROP COLUMN deleted_by;
+ALTER TABLE employees DROP COLUMN updated_by;
+ALTER TABLE employees DROP COLUMN created_by;
@@ -0,0 +1,2 @@
+-- This discards the audit log; export it first if it must be kept
+DROP TABLE IF EXISTS audit_entries;
@@ -0,0 +1,16 @@
+-- One row per employee change. employee_id has no foreign key, so the history
+-- outlives the purge of the employee.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    employee_id bigint NOT NULL,
+    operation varchar(191) NOT NULL,
+    actor varchar(191) NOT NULL,
+    request_id varchar(191) NOT NULL,
+    timestamp datetime(6) NOT NULL,
+    changes longtext NOT NULL
+);
+CREATE INDEX idx_audit_entries_employee_id ON audit_entries (employee_id, id);
+CREATE INDEX idx_audit_entries_timestamp ON audit_entries (timestamp, id);
+-- Entries are immutable: the database refuses to change or remove them
+CREATE TRIGGER audit_entries_no_update BEFORE UPDATE ON audit_entries FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit entries are immutable';
+CREATE TRIGGER audit_entries_no_delete BEFORE DELETE ON audit_entries FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit entries are immutable';
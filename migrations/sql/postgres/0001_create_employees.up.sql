@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS employees (
+    id bigserial PRIMARY KEY,
+    name text,
+    position text,
+    salary decimal
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_employees_salary;
+DROP INDEX IF EXISTS idx_employees_position;
+DROP INDEX IF EXISTS idx_employees_name;
+ALTER TABLE employees
+    ALTER COLUMN name DROP NOT NULL,
+    ALTER COLUMN position DROP NOT NULL,
+    ALTER COLUMN salary DROP NOT NULL;
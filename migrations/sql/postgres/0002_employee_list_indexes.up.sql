@@ -0,0 +1,12 @@
+-- Keyset pagination compares sort columns, which only works without NULLs
+UPDATE employees SET name = '' WHERE name IS NULL;
+UPDATE employees SET position = '' WHERE position IS NULL;
+UPDATE employees SET salary = 0 WHERE salary IS NULL;
+ALTER TABLE employees
+    ALTER COLUMN name SET NOT NULL,
+    ALTER COLUMN position SET NOT NULL,
+    ALTER COLUMN salary SET NOT NULL;
+-- One index per sortable field, with id as the tie-breaker every sort ends on
+CREATE INDEX IF NOT EXISTS idx_employees_name ON employees (name, id);
+CREATE INDEX IF NOT EXISTS idx_employees_position ON employees (position, id);
+CREATE INDEX IF NOT EXISTS idx_employees_salary ON employees (salary, id);
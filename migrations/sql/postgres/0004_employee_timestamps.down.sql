@@ -0,0 +1,2 @@
+ALTER TABLE employees DROP COLUMN updated_at;
+ALTER TABLE employees DROP COLUMN created_at;
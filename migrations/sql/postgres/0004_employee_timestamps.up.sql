@@ -0,0 +1,3 @@
+-- Rows that existed before this migration get its time, not their real creation time
+ALTER TABLE employees ADD COLUMN created_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE employees ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
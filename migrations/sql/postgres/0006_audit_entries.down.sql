@@ -0,0 +1,3 @@
+-- This discards the audit log; export it first if it must be kept
+DROP TABLE IF EXISTS audit_entries;
+DROP FUNCTION IF EXISTS audit_entries_immutable();
@@ -0,0 +1,17 @@
+-- One row per employee change. employee_id has no foreign key, so the history
+-- outlives the purge of the employee.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id bigserial PRIMARY KEY,
+    employee_id bigint NOT NULL,
+    operation text NOT NULL,
+    actor text NOT NULL,
+    request_id text NOT NULL,
+    timestamp timestamptz NOT NULL,
+    changes text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_employee_id ON audit_entries (employee_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_timestamp ON audit_entries (timestamp, id);
+-- Entries are immutable: the database refuses to change or remove them
+CREATE OR REPLACE FUNCTION audit_entries_immutable() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RAISE EXCEPTION 'audit entries are immutable'; END $$;
+CREATE TRIGGER audit_entries_immutable BEFORE UPDATE OR DELETE ON audit_entries FOR EACH ROW EXECUTE FUNCTION audit_entries_immutable();
+CREATE TRIGGER audit_entries_no_truncate BEFORE TRUNCATE ON audit_entries FOR EACH STATEMENT EXECUTE FUNCTION audit_entries_immutable();
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS employees (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text,
+    position text,
+    salary real
+);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_employees_salary;
+DROP INDEX IF EXISTS idx_employees_position;
+DROP INDEX IF EXISTS idx_employees_name;
+CREATE TABLE employees_old (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text,
+    position text,
+    salary real
+);
+INSERT INTO employees_old (id, name, position, salary)
+SELECT id, name, position, salary FROM employees;
+DELETE FROM sqlite_sequence WHERE name = 'employees_old';
+UPDATE sqlite_sequence SET name = 'employees_old' WHERE name = 'employees';
+DROP TABLE employees;
+ALTER TABLE employees_old RENAME TO employees;
@@ -0,0 +1,19 @@
+-- SQLite cannot add NOT NULL to a column, so the table is rebuilt. Keyset
+-- pagination compares sort columns, which only works without NULLs.
+CREATE TABLE employees_new (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    position text NOT NULL,
+    salary real NOT NULL
+);
+INSERT INTO employees_new (id, name, position, salary)
+SELECT id, COALESCE(name, ''), COALESCE(position, ''), COALESCE(salary, 0) FROM employees;
+-- Keep the AUTOINCREMENT high-water mark so deleted IDs are not reused
+DELETE FROM sqlite_sequence WHERE name = 'employees_new';
+UPDATE sqlite_sequence SET name = 'employees_new' WHERE name = 'employees';
+DROP TABLE employees;
+ALTER TABLE employees_new RENAME TO employees;
+-- One index per sortable field, with id as the tie-breaker every sort ends on
+CREATE INDEX idx_employees_name ON employees (name, id);
+CREATE INDEX idx_employees_position ON employees (position, id);
+CREATE INDEX idx_employees_salary ON employees (salary, id);
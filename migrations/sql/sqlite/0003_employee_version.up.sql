@@ -0,0 +1,2 @@
+-- Bumped by every write; updates and deletes only apply to the version they read
+ALTER TABLE employees ADD COLUMN version integer NOT NULL DEFAULT 1;
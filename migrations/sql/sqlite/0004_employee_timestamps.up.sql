@@ -0,0 +1,5 @@
+-- SQLite only adds columns with a constant default, so existing rows are
+-- stamped with the time of this migration afterwards
+ALTER TABLE employees ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE employees ADD COLUMN updated_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+UPDATE employees SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
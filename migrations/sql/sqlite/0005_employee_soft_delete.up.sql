@@ -0,0 +1,7 @@
+-- Who made each change; rows that existed before this migration have no actor
+ALTER TABLE employees ADD COLUMN created_by text NOT NULL DEFAULT '';
+ALTER TABLE employees ADD COLUMN updated_by text NOT NULL DEFAULT '';
+ALTER TABLE employees ADD COLUMN deleted_by text NOT NULL DEFAULT '';
+-- Deleted rows are kept, with deleted_at set, until the purge job removes them
+ALTER TABLE employees ADD COLUMN deleted_at datetime NULL;
+CREATE INDEX IF NOT EXISTS idx_employees_deleted_at ON employees (deleted_at);
@@ -0,0 +1,16 @@
+-- One row per employee change. employee_id has no foreign key, so the history
+-- outlives the purge of the employee.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    employee_id integer NOT NULL,
+    operation text NOT NULL,
+    actor text NOT NULL,
+    request_id text NOT NULL,
+    timestamp datetime NOT NULL,
+    changes text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_employee_id ON audit_entries (employee_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_timestamp ON audit_entries (timestamp, id);
+-- Entries are immutable: the database refuses to change or remove them
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_update BEFORE UPDATE ON audit_entries BEGIN SELECT RAISE(ABORT, 'audit entries are immutable'); END;
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete BEFORE DELETE ON audit_entries BEGIN SELECT RAISE(ABORT, 'audit entries are immutable'); END;
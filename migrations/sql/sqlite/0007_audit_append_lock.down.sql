@@ -0,0 +1 @@
+DROP TABLE IF EXISTS audit_append_lock;
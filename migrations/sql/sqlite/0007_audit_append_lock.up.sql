@@ -0,0 +1,6 @@
+-- Appending an audit entry locks this one row until the transaction ends, so
+-- entries commit in ID order and readers paging by ID never skip one
+CREATE TABLE IF NOT EXISTS audit_append_lock (
+    id integer PRIMARY KEY
+);
+INSERT OR IGNORE INTO audit_append_lock (id) VALUES (1);
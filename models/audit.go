@@ -0,0 +1,65 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Operations recorded in the audit log.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// AuditEntry records one change to an employee. Entries are only ever
+// appended, in the same transaction as the change, and are kept after the
+// employee is purged.
+type AuditEntry struct {
+	ID         int64  `json:"id" gorm:"primary_key"`
+	EmployeeID int    `json:"employee_id"`
+	Operation  string `json:"operation"`
+	// Actor names the caller, as in Employee.UpdatedBy; RequestID ties the
+	// entry to the request's logs.
+	Actor     string       `json:"actor"`
+	RequestID string       `json:"request_id"`
+	Timestamp time.Time    `json:"timestamp"`
+	Changes   AuditChanges `json:"changes"`
+}
+
+// AuditChange is the value of one field before and after a change, keyed by
+// the field's JSON name. Before is nil on create and restore, when the
+// employee appears, and After is nil on delete.
+type AuditChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges is stored as a JSON column.
+type AuditChanges []AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = AuditChanges{}
+	}
+	value, err := json.Marshal(c)
+	return string(value), err
+}
+
+func (c *AuditChanges) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), c)
+	case []byte:
+		return json.Unmarshal(src, c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return errors.New("audit changes must be stored as text")
+	}
+}
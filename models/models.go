@@ -1,8 +1,43 @@
 package models
 
+import (
+	"fmt"
+	"golang-assessment/redact"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Fields tagged `sensitive` are masked in logs and may be hidden from API
+// responses (see package redact).
 type Employee struct {
 	ID       int     `json:"id" gorm:"primary_key"`
-	Name     string  `json:"name"`
+	Name     string  `json:"name" sensitive:"pii"`
 	Position string  `json:"position"`
-	Salary   float64 `json:"salary"`
+	Salary   float64 `json:"salary" sensitive:"financial"`
+	// Version starts at 1 and is bumped by every write. It is sent as the
+	// ETag rather than in the body.
+	Version int `json:"-" gorm:"not null;default:1"`
+	// CreatedAt and UpdatedAt are set by the store.
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// CreatedBy, UpdatedBy and DeletedBy name the caller that made each
+	// change (see services).
+	CreatedBy string `json:"created_by"`
+	UpdatedBy string `json:"updated_by"`
+	DeletedBy string `json:"deleted_by"`
+	// DeletedAt is set by a soft delete. GORM leaves such rows out of every
+	// query unless it is Unscoped.
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+// IsDeleted reports whether the employee has been soft-deleted.
+func (e Employee) IsDeleted() bool {
+	return e.DeletedAt.Valid
+}
+
+// String keeps sensitive fields out of anything that prints an Employee with
+// %v.
+func (e Employee) String() string {
+	return fmt.Sprintf("%v", redact.Value(e))
 }
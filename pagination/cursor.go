@@ -0,0 +1,83 @@
+package pagination
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks where the previous page ended. It is handed to clients as an
+// opaque, encrypted token: the key can hold values the caller may not see,
+// such as salaries, and must not be forged or edited.
+type Cursor struct {
+	// Key holds the sort key values of the last row, ending with its ID.
+	Key []interface{} `json:"k"`
+	// Query identifies the filters and sort the cursor was issued for; it is
+	// only valid with the same ones.
+	Query string `json:"q"`
+}
+
+// CursorCodec encrypts and authenticates cursor tokens with AES-256-GCM.
+type CursorCodec struct {
+	aead cipher.AEAD
+}
+
+// NewCursorCodec derives the cursor key from secret. Every replica must share
+// the secret for cursors to work across them.
+func NewCursorCodec(secret []byte) *CursorCodec {
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		// A 32-byte key is always valid
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &CursorCodec{aead: aead}
+}
+
+// RandomCursorCodec encrypts with a key that only lives as long as the
+// process, so its cursors stop working after a restart.
+func RandomCursorCodec() (*CursorCodec, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return NewCursorCodec(secret), nil
+}
+
+// Encode returns the nonce followed by the sealed payload, base64url-encoded.
+func (c *CursorCodec) Encode(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, payload, nil))
+}
+
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	payload, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || len(cursor.Key) == 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
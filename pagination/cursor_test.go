@@ -0,0 +1,50 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorCodec(t *testing.T) {
+	// Setup
+	codec := NewCursorCodec([]byte("secret"))
+
+	t.Run("round trip", func(t *testing.T) {
+		token := codec.Encode(Cursor{Key: []interface{}{"Developer", 42}, Query: "sort=position,id"})
+		cursor, err := codec.Decode(token)
+		assert.Nil(t, err)
+		// Numbers come back as float64 from JSON
+		assert.Equal(t, Cursor{Key: []interface{}{"Developer", float64(42)}, Query: "sort=position,id"}, cursor)
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		sealed, _ := base64.RawURLEncoding.DecodeString(codec.Encode(Cursor{Key: []interface{}{42}}))
+		sealed[len(sealed)-1] ^= 1
+
+		_, err := codec.Decode(base64.RawURLEncoding.EncodeToString(sealed))
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("payload is not readable", func(t *testing.T) {
+		token := codec.Encode(Cursor{Key: []interface{}{60000, 1}, Query: "sort=salary,id"})
+		sealed, _ := base64.RawURLEncoding.DecodeString(token)
+		assert.NotContains(t, string(sealed), "60000")
+		// Each encoding uses a fresh nonce
+		assert.NotEqual(t, token, codec.Encode(Cursor{Key: []interface{}{60000, 1}, Query: "sort=salary,id"}))
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		token := NewCursorCodec([]byte("other")).Encode(Cursor{Key: []interface{}{42}})
+		_, err := codec.Decode(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "abc.def", "!!.!!"} {
+			_, err := codec.Decode(token)
+			assert.ErrorIs(t, err, ErrInvalidCursor, token)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"golang-assessment/config"
+	"math"
+	"strconv"
+)
+
+// Limits bounds the page size of list requests.
+type Limits struct {
+	Default int
+	Max     int
+	// Clamp lowers a limit above Max to Max instead of rejecting it.
+	Clamp bool
+}
+
+func NewLimits(cfg config.PaginationConfig) Limits {
+	return Limits{Default: cfg.DefaultLimit, Max: cfg.MaxLimit, Clamp: cfg.ClampLimit}
+}
+
+// Limit parses a limit query parameter. An empty value selects the default.
+func (l Limits) Limit(raw string) (int, error) {
+	if raw == "" {
+		return l.Default, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, errors.New("must be a positive integer")
+	}
+	if limit > l.Max {
+		if l.Clamp {
+			return l.Max, nil
+		}
+		return 0, fmt.Errorf("must not exceed %d", l.Max)
+	}
+	return limit, nil
+}
+
+// Page parses a 1-based page number. An empty value selects the first page.
+func Page(raw string, limit int) (int, error) {
+	if raw == "" {
+		return 1, nil
+	}
+	page, err := strconv.Atoi(raw)
+	if err != nil || page < 1 {
+		return 0, errors.New("must be a positive integer")
+	}
+	// The offset (page-1)*limit has to fit in an int
+	if page-1 > math.MaxInt32/limit {
+		return 0, errors.New("is too large")
+	}
+	return page, nil
+}
@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	limits := Limits{Default: 10, Max: 100}
+
+	t.Run("default", func(t *testing.T) {
+		limit, err := limits.Limit("")
+		assert.Nil(t, err)
+		assert.Equal(t, 10, limit)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		limit, err := limits.Limit("100")
+		assert.Nil(t, err)
+		assert.Equal(t, 100, limit)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, raw := range []string{"abc", "0", "-5", "1.5"} {
+			_, err := limits.Limit(raw)
+			assert.EqualError(t, err, "must be a positive integer", raw)
+		}
+	})
+
+	t.Run("above the maximum", func(t *testing.T) {
+		_, err := limits.Limit("10000000")
+		assert.EqualError(t, err, "must not exceed 100")
+	})
+
+	t.Run("clamped", func(t *testing.T) {
+		clamping := Limits{Default: 10, Max: 100, Clamp: true}
+		limit, err := clamping.Limit("10000000")
+		assert.Nil(t, err)
+		assert.Equal(t, 100, limit)
+	})
+}
+
+func TestPage(t *testing.T) {
+	page, err := Page("", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, page)
+
+	page, err = Page("3", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, page)
+
+	for _, raw := range []string{"abc", "0", "-1"} {
+		_, err := Page(raw, 10)
+		assert.EqualError(t, err, "must be a positive integer", raw)
+	}
+
+	_, err = Page("999999999999", 100)
+	assert.EqualError(t, err, "is too large")
+}
@@ -0,0 +1,319 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operation is one step of a JSON Patch.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  *string         `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is an RFC 6902 JSON Patch: a list of operations applied in order.
+// If any of them fails none take effect.
+type JSONPatch []Operation
+
+func ParseJSONPatch(body []byte) (JSONPatch, error) {
+	var operations JSONPatch
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	for i, operation := range operations {
+		if err := operation.validate(); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalid, i, err)
+		}
+	}
+	return operations, nil
+}
+
+func (o Operation) validate() error {
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return fmt.Errorf("%s needs a value", o.Op)
+		}
+	case "move", "copy":
+		if o.From == nil {
+			return fmt.Errorf("%s needs a from", o.Op)
+		}
+		if _, err := parsePointer(*o.From); err != nil {
+			return fmt.Errorf("from: %v", err)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", o.Op)
+	}
+	if _, err := parsePointer(o.Path); err != nil {
+		return fmt.Errorf("path: %v", err)
+	}
+	return nil
+}
+
+func (p JSONPatch) Apply(doc []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, operation := range p {
+		target, err = operation.apply(target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d (%s %s): %v", ErrApply, i, operation.Op, operation.Path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func (o Operation) apply(doc interface{}) (interface{}, error) {
+	path, _ := parsePointer(o.Path)
+	switch o.Op {
+	case "add":
+		value, err := decode(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "remove":
+		return remove(doc, path)
+	case "replace":
+		value, err := decode(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		// The whole document always exists, so it can be replaced though not
+		// removed
+		if len(path) == 0 {
+			return value, nil
+		}
+		if doc, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "move":
+		from, _ := parsePointer(*o.From)
+		if isProperPrefix(from, path) {
+			return nil, fmt.Errorf("cannot move %s into itself", *o.From)
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "copy":
+		from, _ := parsePointer(*o.From)
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		// Copy through JSON so the two locations do not share maps or slices
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = decode(raw); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default: // test
+		expected, err := decode(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(actual, expected) {
+			return nil, fmt.Errorf("test failed, value is %s", jsonString(actual))
+		}
+		return doc, nil
+	}
+}
+
+func isProperPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%q must be empty or start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%q does not exist", token)
+			}
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("cannot index a scalar with %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// update replaces the container holding the last token of path with the
+// result of change, rebuilding the containers above it.
+func update(doc interface{}, path []string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return change(doc, path[0])
+	}
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = update(child, path[1:], change); err != nil {
+		return nil, err
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(node)-1)
+		node[index] = child
+	}
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to a scalar", token)
+		}
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("%q does not exist", token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q from a scalar", token)
+		}
+	})
+}
+
+// arrayIndex parses an array index token, which may be at most max.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || strconv.Itoa(index) != token {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+	if index > max {
+		return 0, fmt.Errorf("index %d is out of range", index)
+	}
+	return index, nil
+}
+
+// equal compares JSON values, treating numbers as equal when their values
+// are, so 1 equals 1.0.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func jsonString(value interface{}) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
@@ -0,0 +1,47 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch is an RFC 7396 JSON Merge Patch: an object whose members
+// replace those of the target, with null removing a member.
+type MergePatch struct {
+	patch interface{}
+}
+
+func ParseMergePatch(body []byte) (MergePatch, error) {
+	value, err := decode(body)
+	if err != nil {
+		return MergePatch{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return MergePatch{patch: value}, nil
+}
+
+func (p MergePatch) Apply(doc []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p.patch))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = merge(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
@@ -0,0 +1,57 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON values.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupportedType is returned by Parse for any other media type.
+	ErrUnsupportedType = errors.New("unsupported patch media type")
+	// ErrInvalid means the patch document itself is malformed.
+	ErrInvalid = errors.New("invalid patch")
+	// ErrApply means a well-formed patch cannot be applied to the document,
+	// e.g. a path does not exist or a test operation failed.
+	ErrApply = errors.New("patch cannot be applied")
+)
+
+// Patch changes a JSON document.
+type Patch interface {
+	Apply(doc []byte) ([]byte, error)
+}
+
+// Parse decodes a patch of the given media type.
+func Parse(mediaType string, body []byte) (Patch, error) {
+	switch mediaType {
+	case MergePatchType:
+		return ParseMergePatch(body)
+	case JSONPatchType:
+		return ParseJSONPatch(body)
+	default:
+		return nil, fmt.Errorf("%w %q, use %s or %s", ErrUnsupportedType, mediaType, MergePatchType, JSONPatchType)
+	}
+}
+
+// decode unmarshals JSON keeping numbers as json.Number, so they pass
+// through a patch unchanged.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
@@ -0,0 +1,113 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const employee = `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"tags":["a","b"]}`
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"replace a member", `{"position":"Manager"}`, `{"id":1,"name":"John Doe","position":"Manager","salary":60000,"tags":["a","b"]}`},
+		{"remove a member", `{"tags":null}`, `{"id":1,"name":"John Doe","position":"Developer","salary":60000}`},
+		{"empty patch", `{}`, employee},
+		{"non-object patch replaces the document", `[1]`, `[1]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseMergePatch([]byte(tc.patch))
+			assert.Nil(t, err)
+			result, err := p.Apply([]byte(employee))
+			assert.Nil(t, err)
+			assert.JSONEq(t, tc.want, string(result))
+		})
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ParseMergePatch([]byte(`{"name":`))
+		assert.True(t, errors.Is(err, ErrInvalid))
+	})
+}
+
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"replace", `[{"op":"replace","path":"/salary","value":65000}]`, `{"id":1,"name":"John Doe","position":"Developer","salary":65000,"tags":["a","b"]}`},
+		{"add and remove", `[{"op":"add","path":"/tags/1","value":"x"},{"op":"remove","path":"/tags/0"}]`, `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"tags":["x","b"]}`},
+		{"append", `[{"op":"add","path":"/tags/-","value":"c"}]`, `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"tags":["a","b","c"]}`},
+		{"move", `[{"op":"move","from":"/name","path":"/position"}]`, `{"id":1,"position":"John Doe","salary":60000,"tags":["a","b"]}`},
+		{"copy", `[{"op":"copy","from":"/tags","path":"/labels"}]`, `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"tags":["a","b"],"labels":["a","b"]}`},
+		{"passing test", `[{"op":"test","path":"/salary","value":60000.0},{"op":"replace","path":"/salary","value":1}]`, `{"id":1,"name":"John Doe","position":"Developer","salary":1,"tags":["a","b"]}`},
+		{"replace the document", `[{"op":"replace","path":"","value":{"name":"Jane Doe"}}]`, `{"name":"Jane Doe"}`},
+		{"escaped pointer", `[{"op":"add","path":"/a~1b~0c","value":true}]`, `{"id":1,"name":"John Doe","position":"Developer","salary":60000,"tags":["a","b"],"a/b~c":true}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseJSONPatch([]byte(tc.patch))
+			assert.Nil(t, err)
+			result, err := p.Apply([]byte(employee))
+			assert.Nil(t, err)
+			assert.JSONEq(t, tc.want, string(result))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, body := range []string{
+			`{"op":"add"}`,
+			`[{"op":"rename","path":"/name"}]`,
+			`[{"op":"replace","path":"/name"}]`,
+			`[{"op":"remove","path":"name"}]`,
+			`[{"op":"move","path":"/name"}]`,
+		} {
+			_, err := ParseJSONPatch([]byte(body))
+			assert.True(t, errors.Is(err, ErrInvalid), body)
+		}
+	})
+
+	t.Run("cannot be applied", func(t *testing.T) {
+		for _, body := range []string{
+			`[{"op":"replace","path":"/age","value":1}]`,
+			`[{"op":"remove","path":"/tags/2"}]`,
+			`[{"op":"add","path":"/tags/01","value":"x"}]`,
+			`[{"op":"test","path":"/salary","value":"60000"}]`,
+			`[{"op":"move","from":"/tags","path":"/tags/0"}]`,
+			`[{"op":"move","from":"","path":"/copy"}]`,
+			`[{"op":"remove","path":""}]`,
+		} {
+			p, err := ParseJSONPatch([]byte(body))
+			assert.Nil(t, err, body)
+			_, err = p.Apply([]byte(employee))
+			assert.True(t, errors.Is(err, ErrApply), body)
+		}
+	})
+
+	t.Run("failed test leaves the document alone", func(t *testing.T) {
+		p, _ := ParseJSONPatch([]byte(`[{"op":"replace","path":"/salary","value":0},{"op":"test","path":"/name","value":"Jane"}]`))
+		result, err := p.Apply([]byte(employee))
+		assert.EqualError(t, err, `patch cannot be applied: operation 1 (test /name): test failed, value is "John Doe"`)
+		assert.Nil(t, result)
+	})
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse("application/json", []byte(`{}`))
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+
+	p, err := Parse(MergePatchType, []byte(`{}`))
+	assert.Nil(t, err)
+	assert.IsType(t, MergePatch{}, p)
+
+	p, err = Parse(JSONPatchType, []byte(`[]`))
+	assert.Nil(t, err)
+	assert.IsType(t, JSONPatch{}, p)
+}
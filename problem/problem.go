@@ -0,0 +1,148 @@
+// Package problem renders errors as RFC 7807 problem details
+// (application/problem+json), so every error response has the same shape
+// and a stable, machine-readable code.
+package problem
+
+import (
+	"errors"
+	"golang-assessment/apperrors"
+	"golang-assessment/logger"
+	repository "golang-assessment/respository"
+	"golang-assessment/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// middlewareKey marks requests that Middleware will answer.
+const middlewareKey = "problem.middleware"
+
+// Codes name the kinds of problem. They are part of the API: clients may
+// branch on them, so existing codes must not change meaning.
+const (
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidBody          = "invalid_body"
+	CodeInvalidQuery         = "invalid_query"
+	CodeInvalidPatch         = "invalid_patch"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeIfMatchRequired      = "if_match_required"
+	CodeRouteNotFound        = "route_not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeVersionMismatch      = "version_mismatch"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnavailable          = "unavailable"
+	CodeInternal             = "internal"
+)
+
+// Problem is an RFC 7807 problem details object. Type is a URI reference
+// derived from Code, relative to the API's base URL.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	// Parameter names the query parameter that was rejected.
+	Parameter string `json:"parameter,omitempty"`
+	// Errors lists every field that failed validation.
+	Errors []validation.FieldError `json:"errors,omitempty"`
+}
+
+// New returns a problem titled after its status.
+func New(status int, code, detail string) *Problem {
+	return &Problem{Type: "/problems/" + code, Title: http.StatusText(status), Status: status, Detail: detail, Code: code}
+}
+
+// InvalidQuery is a 400 for one rejected query parameter.
+func InvalidQuery(parameter, message string) *Problem {
+	p := New(http.StatusBadRequest, CodeInvalidQuery, parameter+": "+message)
+	p.Parameter = parameter
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Detail
+}
+
+// From maps err to the problem the client sees. A *Problem is used as it
+// is; domain errors get the status of their kind. The details of unavailable
+// and unknown errors may come from the database, so they are not shown.
+func From(c *gin.Context, err error) *Problem {
+	var p *Problem
+	var invalid *validation.Error
+	switch {
+	case errors.As(err, &p):
+		copied := *p
+		return &copied
+	case errors.Is(err, repository.ErrVersionMismatch):
+		// 412 when the client sent If-Match, 409 when it lost a race it did
+		// not guard against
+		if c.GetHeader("If-Match") != "" {
+			return New(http.StatusPreconditionFailed, CodeVersionMismatch, err.Error())
+		}
+		return New(http.StatusConflict, CodeVersionMismatch, err.Error())
+	case errors.As(err, &invalid):
+		p = New(http.StatusUnprocessableEntity, CodeValidationFailed, "one or more fields are invalid")
+		p.Errors = invalid.Fields
+		return p
+	case errors.Is(err, apperrors.ErrNotFound):
+		return New(http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, apperrors.ErrConflict):
+		return New(http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, apperrors.ErrValidation):
+		return New(http.StatusUnprocessableEntity, CodeValidationFailed, err.Error())
+	case errors.Is(err, apperrors.ErrUnavailable):
+		return New(http.StatusServiceUnavailable, CodeUnavailable, "the service is temporarily unavailable, try again later")
+	default:
+		return New(http.StatusInternalServerError, CodeInternal, "")
+	}
+}
+
+// Write renders err as a problem and aborts the request.
+func Write(c *gin.Context, err error) {
+	p := From(c, err)
+	p.Instance = c.Request.URL.RequestURI()
+	p.RequestID = logger.RequestIDFromContext(c.Request.Context())
+	if p.Status == http.StatusServiceUnavailable {
+		c.Header("Retry-After", "1")
+	}
+	// gin keeps a content type that is already set
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Middleware renders the last error a handler added with c.Error, unless
+// the handler already wrote a response. Handlers then only need to record
+// the error and return.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middlewareKey, true)
+		c.Next()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			Write(c, c.Errors.Last().Err)
+		}
+	}
+}
+
+// Fail records err for Middleware to render and stops the handler chain.
+// On a route without Middleware it writes the problem itself, rather than
+// leave the request without a response.
+func Fail(c *gin.Context, err error) {
+	_ = c.Error(err)
+	if _, ok := c.Get(middlewareKey); !ok {
+		Write(c, err)
+		return
+	}
+	c.Abort()
+}
@@ -0,0 +1,122 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	repository "golang-assessment/respository"
+	"golang-assessment/validation"
+)
+
+func serve(router *gin.Engine, header string) (*httptest.ResponseRecorder, Problem) {
+	req, _ := http.NewRequest(http.MethodGet, "/thing?x=1", nil)
+	if header != "" {
+		req.Header.Set("If-Match", header)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var p Problem
+	_ = json.Unmarshal(rr.Body.Bytes(), &p)
+	return rr, p
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("TestMiddleware_RendersLastError", func(t *testing.T) {
+		router := gin.New()
+		router.Use(Middleware())
+		router.GET("/thing", func(c *gin.Context) {
+			_ = c.Error(errors.New("ignored"))
+			Fail(c, InvalidQuery("x", "must be a letter"))
+		})
+
+		rr, p := serve(router, "")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+		assert.Equal(t, Problem{
+			Type:      "/problems/invalid_query",
+			Title:     "Bad Request",
+			Status:    http.StatusBadRequest,
+			Detail:    "x: must be a letter",
+			Instance:  "/thing?x=1",
+			Code:      CodeInvalidQuery,
+			Parameter: "x",
+		}, p)
+	})
+
+	t.Run("TestMiddleware_KeepsWrittenResponse", func(t *testing.T) {
+		router := gin.New()
+		router.Use(Middleware())
+		router.GET("/thing", func(c *gin.Context) {
+			_ = c.Error(errors.New("logged only"))
+			c.String(http.StatusAccepted, "done")
+		})
+
+		rr, _ := serve(router, "")
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, "done", rr.Body.String())
+	})
+
+	t.Run("TestFail_WithoutMiddleware", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/thing", func(c *gin.Context) {
+			Fail(c, repository.ErrEmployeeNotFound)
+		})
+
+		rr, p := serve(router, "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, CodeNotFound, p.Code)
+	})
+}
+
+func TestFrom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	from := func(err error, ifMatch string) *Problem {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest(http.MethodPut, "/employees/1", nil)
+		if ifMatch != "" {
+			c.Request.Header.Set("If-Match", ifMatch)
+		}
+		return From(c, err)
+	}
+
+	t.Run("TestFrom_VersionMismatch", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionFailed, from(repository.ErrVersionMismatch, `"1"`).Status)
+		assert.Equal(t, http.StatusConflict, from(repository.ErrVersionMismatch, "").Status)
+		assert.Equal(t, CodeVersionMismatch, from(repository.ErrVersionMismatch, "").Code)
+	})
+
+	t.Run("TestFrom_ValidationErrors", func(t *testing.T) {
+		fields := []validation.FieldError{{Field: "name", Message: "is required"}}
+		p := from(&validation.Error{Fields: fields}, "")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+		assert.Equal(t, CodeValidationFailed, p.Code)
+		assert.Equal(t, fields, p.Errors)
+	})
+
+	t.Run("TestFrom_Unknown", func(t *testing.T) {
+		p := from(errors.New(`pq: relation "employees" does not exist`), "")
+
+		assert.Equal(t, http.StatusInternalServerError, p.Status)
+		assert.Equal(t, CodeInternal, p.Code)
+		assert.Empty(t, p.Detail)
+	})
+
+	t.Run("TestFrom_DoesNotShareProblems", func(t *testing.T) {
+		shared := New(http.StatusBadRequest, CodeInvalidID, "invalid ID")
+		from(shared, "").Instance = "/changed"
+
+		assert.Empty(t, shared.Instance)
+	})
+}
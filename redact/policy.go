@@ -0,0 +1,31 @@
+package redact
+
+import "golang-assessment/config"
+
+// Policy decides which sensitive fields a caller may see in API responses.
+type Policy struct {
+	salaryRoles map[string]bool
+}
+
+func NewPolicy(cfg config.RedactionConfig) *Policy {
+	policy := &Policy{salaryRoles: make(map[string]bool, len(cfg.SalaryRoles))}
+	for _, role := range cfg.SalaryRoles {
+		policy.salaryRoles[role] = true
+	}
+	return policy
+}
+
+// Response returns v as a caller with the given role may see it. Callers
+// allowed to see everything get v back unchanged.
+func (p *Policy) Response(role string, v interface{}) interface{} {
+	if p.salaryRoles[role] {
+		return v
+	}
+	return Omit(v, ClassFinancial)
+}
+
+// Hides reports whether fields of class are hidden from a caller with the
+// given role.
+func (p *Policy) Hides(role, class string) bool {
+	return class == ClassFinancial && !p.salaryRoles[role]
+}
@@ -0,0 +1,179 @@
+package redact
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Fields are marked with `sensitive:"<class>"`. The class lets a Policy hide
+// some kinds of data from some callers; logs mask every class.
+const (
+	tagName = "sensitive"
+
+	ClassPII       = "pii"
+	ClassFinancial = "financial"
+)
+
+// Mask replaces sensitive values in logs.
+const Mask = "[REDACTED]"
+
+// Value returns v with every sensitive field replaced by Mask. Structs that
+// contain sensitive fields come back as maps keyed by their JSON names;
+// anything else is returned unchanged.
+func Value(v interface{}) interface{} {
+	return transform(v, func(string) bool { return true }, true)
+}
+
+// Omit returns v without the fields of the given classes, in the same shape
+// Value uses.
+func Omit(v interface{}, classes ...string) interface{} {
+	return transform(v, func(class string) bool {
+		for _, c := range classes {
+			if c == class {
+				return true
+			}
+		}
+		return false
+	}, false)
+}
+
+// Class returns the class of the field of struct v with the given JSON name,
+// or "" when the field is not sensitive or does not exist. It is for data
+// that names fields rather than holding them, such as an audit log.
+func Class(v interface{}, name string) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() && jsonName(field) == name {
+			return field.Tag.Get(tagName)
+		}
+	}
+	return ""
+}
+
+func transform(v interface{}, hide func(class string) bool, mask bool) interface{} {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() || !sensitive(value.Type()) {
+		return v
+	}
+	return walk(value, hide, mask)
+}
+
+func walk(v reflect.Value, hide func(class string) bool, mask bool) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walk(v.Elem(), hide, mask)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = walk(v.Index(i), hide, mask)
+		}
+		return items
+	case reflect.Struct:
+		if !sensitive(v.Type()) {
+			return v.Interface()
+		}
+		t := v.Type()
+		fields := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			// The map is marshalled in place of the struct, so it keeps the
+			// struct's omitempty
+			if omitEmpty(field) && isEmpty(v.Field(i)) {
+				continue
+			}
+			if class := field.Tag.Get(tagName); class != "" && hide(class) {
+				if mask {
+					fields[name] = Mask
+				}
+				continue
+			}
+			fields[name] = walk(v.Field(i), hide, mask)
+		}
+		return fields
+	default:
+		return v.Interface()
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func omitEmpty(field reflect.StructField) bool {
+	_, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty is encoding/json's notion of an empty value.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+var sensitiveTypes sync.Map // reflect.Type -> bool
+
+// sensitive reports whether values of type t can hold a sensitive field.
+func sensitive(t reflect.Type) bool {
+	if cached, ok := sensitiveTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := containsSensitive(t, map[reflect.Type]bool{})
+	sensitiveTypes.Store(t, result)
+	return result
+}
+
+func containsSensitive(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return containsSensitive(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get(tagName) != "" || containsSensitive(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
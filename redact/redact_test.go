@@ -0,0 +1,88 @@
+package redact
+
+import (
+	"testing"
+	"time"
+
+	"golang-assessment/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	ID      int     `json:"id"`
+	Owner   string  `json:"owner" sensitive:"pii"`
+	Balance float64 `json:"balance" sensitive:"financial"`
+	Note    string  `json:"-"`
+}
+
+type plain struct {
+	ID int `json:"id"`
+}
+
+func TestValue(t *testing.T) {
+	t.Run("masks every sensitive field", func(t *testing.T) {
+		result := Value(account{ID: 1, Owner: "John Doe", Balance: 100, Note: "hidden"})
+		assert.Equal(t, map[string]interface{}{"id": 1, "owner": Mask, "balance": Mask}, result)
+	})
+
+	t.Run("pointers and slices", func(t *testing.T) {
+		result := Value([]*account{{ID: 1, Owner: "John Doe"}, nil})
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": 1, "owner": Mask, "balance": Mask},
+			nil,
+		}, result)
+	})
+
+	t.Run("values without sensitive fields are unchanged", func(t *testing.T) {
+		assert.Equal(t, plain{ID: 1}, Value(plain{ID: 1}))
+		assert.Equal(t, "text", Value("text"))
+		assert.Nil(t, Value(nil))
+	})
+}
+
+func TestOmit(t *testing.T) {
+	result := Omit(account{ID: 1, Owner: "John Doe", Balance: 100}, ClassFinancial)
+	assert.Equal(t, map[string]interface{}{"id": 1, "owner": "John Doe"}, result)
+
+	t.Run("keeps omitempty", func(t *testing.T) {
+		type closed struct {
+			ID       int        `json:"id"`
+			Balance  float64    `json:"balance" sensitive:"financial"`
+			ClosedBy string     `json:"closed_by,omitempty"`
+			ClosedAt *time.Time `json:"closed_at,omitempty"`
+		}
+		assert.Equal(t, map[string]interface{}{"id": 1}, Omit(closed{ID: 1}, ClassFinancial))
+		assert.Equal(t, map[string]interface{}{"id": 1, "closed_by": "jane"}, Omit(closed{ID: 1, ClosedBy: "jane"}, ClassFinancial))
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	// Setup
+	policy := NewPolicy(config.RedactionConfig{SalaryRoles: []string{"hr"}})
+	employee := account{ID: 1, Owner: "John Doe", Balance: 100}
+
+	t.Run("allowed role sees everything", func(t *testing.T) {
+		assert.Equal(t, employee, policy.Response("hr", employee))
+	})
+
+	t.Run("other roles lose financial fields", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{"id": 1, "owner": "John Doe"}, policy.Response("viewer", employee))
+		assert.Equal(t, map[string]interface{}{"id": 1, "owner": "John Doe"}, policy.Response("", employee))
+	})
+
+	t.Run("hides", func(t *testing.T) {
+		assert.False(t, policy.Hides("hr", ClassFinancial))
+		assert.True(t, policy.Hides("viewer", ClassFinancial))
+		assert.False(t, policy.Hides("viewer", ClassPII))
+		assert.False(t, policy.Hides("viewer", ""))
+	})
+}
+
+func TestClass(t *testing.T) {
+	assert.Equal(t, ClassFinancial, Class(account{}, "balance"))
+	assert.Equal(t, ClassPII, Class(&account{}, "owner"))
+	assert.Equal(t, "", Class(account{}, "id"))
+	assert.Equal(t, "", Class(account{}, "missing"))
+	assert.Equal(t, "", Class(42, "balance"))
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"golang-assessment/models"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AuditStore keeps the audit log. It has no way to change or remove an
+// entry; the migrations also make the table refuse updates and deletes.
+type AuditStore interface {
+	// AppendAudit stores entry, setting its ID and Timestamp. Called on the
+	// store of a WithTx, the entry commits or rolls back with the change it
+	// records, and the transaction holds the global append lock until then.
+	AppendAudit(ctx context.Context, entry *models.AuditEntry) error
+	// ListAudit returns up to limit entries matching the query with an ID
+	// above after, oldest first. Appends are serialized, so entries become
+	// visible in ID order and after works as a cursor.
+	ListAudit(ctx context.Context, query AuditQuery, after int64, limit int) ([]models.AuditEntry, error)
+}
+
+// AuditQuery selects audit entries. Zero fields match every entry.
+type AuditQuery struct {
+	EmployeeID int
+	// Since keeps the entries recorded at or after it.
+	Since time.Time
+}
+
+// String identifies the query, for tracing and for binding cursors to it.
+func (q AuditQuery) String() string {
+	values := url.Values{}
+	if q.EmployeeID != 0 {
+		values.Set("employee_id", strconv.Itoa(q.EmployeeID))
+	}
+	if !q.Since.IsZero() {
+		values.Set("since", q.Since.UTC().Format(time.RFC3339Nano))
+	}
+	return values.Encode()
+}
+
+func (q AuditQuery) matches(entry models.AuditEntry) bool {
+	if q.EmployeeID != 0 && entry.EmployeeID != q.EmployeeID {
+		return false
+	}
+	return q.Since.IsZero() || !entry.Timestamp.Before(q.Since)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"golang-assessment/config"
+	"golang-assessment/models"
+)
+
+func (s *MemoryEmployeeStore) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	defer s.lock()()
+
+	s.nextAuditID++
+	entry.ID = s.nextAuditID
+	entry.Timestamp = config.Now()
+	s.audit = append(s.audit, *entry)
+
+	s.log.WithContext(ctx).WithField("employee_id", entry.EmployeeID).Debug("Audit entry appended")
+	return nil
+}
+
+func (s *MemoryEmployeeStore) ListAudit(ctx context.Context, query AuditQuery, after int64, limit int) ([]models.AuditEntry, error) {
+	defer s.rlock()()
+
+	// s.audit is in ID order already
+	entries := []models.AuditEntry{}
+	for _, entry := range s.audit {
+		if len(entries) == limit {
+			break
+		}
+		if entry.ID > after && query.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	s.log.WithContext(ctx).WithField("count", len(entries)).Debug("Listed audit entries")
+	return entries, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"golang-assessment/config"
+	"golang-assessment/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditAppendLock is the single row appends lock, see AppendAudit.
+type auditAppendLock struct {
+	ID int
+}
+
+func (auditAppendLock) TableName() string {
+	return "audit_append_lock"
+}
+
+// AppendAudit takes the append lock before the entry gets its ID and keeps it
+// until the enclosing transaction ends. Without it a transaction could commit
+// a lower ID after a reader had paged past a higher one, and the reader would
+// never see that entry. Within a WithTx the nested transaction is a
+// savepoint, which does not release the lock.
+func (r *EmployeeRepository) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var lock auditAppendLock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lock, 1).Error; err != nil {
+			return err
+		}
+		entry.Timestamp = config.Now()
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("Error appending audit entry: %v", err)
+		return translate(err)
+	}
+
+	r.log.WithContext(ctx).WithField("employee_id", entry.EmployeeID).Debug("Audit entry appended")
+	return nil
+}
+
+func (r *EmployeeRepository) ListAudit(ctx context.Context, query AuditQuery, after int64, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+
+	db := r.db.WithContext(ctx).Where("id > ?", after)
+	if query.EmployeeID != 0 {
+		db = db.Where("employee_id = ?", query.EmployeeID)
+	}
+	if !query.Since.IsZero() {
+		db = db.Where("timestamp >= ?", query.Since.UTC())
+	}
+	if err := db.Order("id").Limit(limit).Find(&entries).Error; err != nil {
+		r.log.WithContext(ctx).Errorf("Error listing audit entries: %v", err)
+		return nil, translate(err)
+	}
+
+	r.log.WithContext(ctx).WithField("count", len(entries)).Debug("Listed audit entries")
+	return entries, nil
+}
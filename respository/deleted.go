@@ -0,0 +1,17 @@
+package repository
+
+import "context"
+
+type includeDeletedKey struct{}
+
+// IncludeDeleted returns a context in which store reads also return
+// soft-deleted employees. Writes ignore it: only RestoreEmployee and
+// PurgeEmployees apply to deleted employees.
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+func includesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
@@ -0,0 +1,53 @@
+package repository
+
+import "golang-assessment/models"
+
+// EmployeeChanges holds the fields a partial update sets. Nil fields are
+// left as they are.
+type EmployeeChanges struct {
+	Name     *string
+	Position *string
+	Salary   *float64
+	// UpdatedBy is who makes the changes. It is written with them but is not
+	// a change of its own.
+	UpdatedBy string
+}
+
+func (c EmployeeChanges) IsEmpty() bool {
+	return c.Name == nil && c.Position == nil && c.Salary == nil
+}
+
+// ApplyTo sets the changed fields on employee.
+func (c EmployeeChanges) ApplyTo(employee *models.Employee) {
+	if c.Name != nil {
+		employee.Name = *c.Name
+	}
+	if c.Position != nil {
+		employee.Position = *c.Position
+	}
+	if c.Salary != nil {
+		employee.Salary = *c.Salary
+	}
+	if c.UpdatedBy != "" {
+		employee.UpdatedBy = c.UpdatedBy
+	}
+}
+
+// columns maps each changed column to its new value, for an UPDATE that
+// touches only those columns.
+func (c EmployeeChanges) columns() map[string]interface{} {
+	columns := map[string]interface{}{}
+	if c.Name != nil {
+		columns[fieldColumns["name"]] = *c.Name
+	}
+	if c.Position != nil {
+		columns[fieldColumns["position"]] = *c.Position
+	}
+	if c.Salary != nil {
+		columns[fieldColumns["salary"]] = *c.Salary
+	}
+	if c.UpdatedBy != "" {
+		columns[fieldColumns["updated_by"]] = c.UpdatedBy
+	}
+	return columns
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"golang-assessment/apperrors"
+	"golang-assessment/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchEmployee(t *testing.T) {
+	position := "Lead"
+	for name, store := range setupListStores(t) {
+		t.Run(name, func(t *testing.T) {
+			version, err := store.PatchEmployee(context.Background(), 1, 1, EmployeeChanges{Position: &position})
+			assert.Nil(t, err)
+			assert.Equal(t, 2, version)
+
+			employee, err := store.GetEmployeeByID(context.Background(), 1, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, models.Employee{ID: 1, Name: "John Doe", Position: "Lead", Salary: 60000, Version: 2}, withoutTimestamps(employee))
+			assert.True(t, employee.UpdatedAt.After(employee.CreatedAt))
+
+			_, err = store.PatchEmployee(context.Background(), 1, 1, EmployeeChanges{Position: &position})
+			assert.ErrorIs(t, err, ErrVersionMismatch)
+
+			_, err = store.PatchEmployee(context.Background(), 100, 1, EmployeeChanges{Position: &position})
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+		})
+	}
+}
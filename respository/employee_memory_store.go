@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"golang-assessment/config"
+	"golang-assessment/models"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type MemoryEmployeeStore struct {
+	log *logrus.Logger
+	mu  *sync.RWMutex
+	// tx marks the store handed to a WithTx function. It works on a copy of
+	// the employees, and the enclosing WithTx already holds mu.
+	tx        bool
+	employees map[int]models.Employee
+	nextID    int
+	// audit is append-only and in ID order.
+	audit       []models.AuditEntry
+	nextAuditID int64
+}
+
+func NewMemoryEmployeeStore(log *logrus.Logger) *MemoryEmployeeStore {
+	return &MemoryEmployeeStore{log: log, mu: &sync.RWMutex{}, employees: make(map[int]models.Employee)}
+}
+
+// lock and rlock take the store's lock unless a transaction already holds it,
+// and return the function that releases it.
+func (s *MemoryEmployeeStore) lock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *MemoryEmployeeStore) rlock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// WithTx holds the write lock for the whole transaction, so transactions run
+// one at a time, and commits by swapping in the copy fn worked on. A nested
+// WithTx copies again, which makes it a savepoint.
+func (s *MemoryEmployeeStore) WithTx(ctx context.Context, fn func(store EmployeeStore) error) error {
+	defer s.lock()()
+
+	// Clipping makes the transaction's appends copy the audit log instead of
+	// writing into the array s.audit shares
+	tx := &MemoryEmployeeStore{
+		log: s.log, mu: s.mu, tx: true,
+		employees: maps.Clone(s.employees), nextID: s.nextID,
+		audit: slices.Clip(s.audit), nextAuditID: s.nextAuditID,
+	}
+	// Like a database sequence, IDs handed out are not reused after a rollback
+	defer func() { s.nextID, s.nextAuditID = tx.nextID, tx.nextAuditID }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.employees, s.audit = tx.employees, tx.audit
+	return nil
+}
+
+func (s *MemoryEmployeeStore) CreateEmployee(ctx context.Context, employee *models.Employee) error {
+	defer s.lock()()
+
+	// IDs are never reused, even after a delete, to match a database sequence.
+	s.nextID++
+	employee.ID = s.nextID
+	employee.Version = 1
+	employee.CreatedAt = config.Now()
+	employee.UpdatedAt = employee.CreatedAt
+	s.employees[employee.ID] = *employee
+	s.log.WithContext(ctx).WithField("employee_id", employee.ID).Info("Employee created")
+	return nil
+}
+
+func (s *MemoryEmployeeStore) GetEmployeeByID(ctx context.Context, id int, fields Fields) (models.Employee, error) {
+	defer s.rlock()()
+
+	employee, ok := s.employees[id]
+	if !ok || !visible(ctx, employee) {
+		s.log.WithContext(ctx).Errorf("Error retreiving employee by ID %d:%v", id, ErrEmployeeNotFound)
+		return models.Employee{}, ErrEmployeeNotFound
+	}
+
+	s.log.WithContext(ctx).WithField("employee_id", employee.ID).Debug("Retrieved employee")
+	return project(employee, fields.columns()), nil
+}
+
+func (s *MemoryEmployeeStore) UpdateEmployee(ctx context.Context, employee *models.Employee) error {
+	defer s.lock()()
+
+	if err := s.checkVersion(employee.ID, employee.Version); err != nil {
+		s.log.WithContext(ctx).Errorf("Error updating employee :%v", err)
+		return err
+	}
+	employee.Version++
+	employee.UpdatedAt = config.Now()
+	// Only what a database UPDATE would write comes from employee
+	current, stored := s.employees[employee.ID], *employee
+	stored.CreatedAt, stored.CreatedBy = current.CreatedAt, current.CreatedBy
+	stored.DeletedAt, stored.DeletedBy = current.DeletedAt, current.DeletedBy
+	s.employees[employee.ID] = stored
+
+	s.log.WithContext(ctx).WithField("employee_id", employee.ID).Info("Employee updated")
+	return nil
+}
+
+func (s *MemoryEmployeeStore) PatchEmployee(ctx context.Context, id, version int, changes EmployeeChanges) (int, error) {
+	defer s.lock()()
+
+	if err := s.checkVersion(id, version); err != nil {
+		s.log.WithContext(ctx).Errorf("Error patching employee by ID %d: %v", id, err)
+		return 0, err
+	}
+	employee := s.employees[id]
+	changes.ApplyTo(&employee)
+	employee.Version++
+	employee.UpdatedAt = config.Now()
+	s.employees[id] = employee
+
+	s.log.WithContext(ctx).WithField("employee_id", id).Info("Employee patched")
+	return employee.Version, nil
+}
+
+func (s *MemoryEmployeeStore) DeleteEmployee(ctx context.Context, id, version int, actor string) error {
+	defer s.lock()()
+
+	if err := s.checkVersion(id, version); err != nil {
+		return err
+	}
+	employee := s.employees[id]
+	now := config.Now()
+	employee.Version++
+	employee.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	employee.DeletedBy = actor
+	employee.UpdatedAt = now
+	employee.UpdatedBy = actor
+	s.employees[id] = employee
+
+	s.log.WithContext(ctx).Infof("Employee deleted with ID %d", id)
+	return nil
+}
+
+func (s *MemoryEmployeeStore) RestoreEmployee(ctx context.Context, id, version int, actor string) (int, error) {
+	defer s.lock()()
+
+	employee, ok := s.employees[id]
+	if !ok {
+		return 0, ErrEmployeeNotFound
+	}
+	if employee.Version != version {
+		return 0, ErrVersionMismatch
+	}
+	employee.Version++
+	employee.DeletedAt, employee.DeletedBy = gorm.DeletedAt{}, ""
+	employee.UpdatedAt = config.Now()
+	employee.UpdatedBy = actor
+	s.employees[id] = employee
+
+	s.log.WithContext(ctx).WithField("employee_id", id).Info("Employee restored")
+	return employee.Version, nil
+}
+
+func (s *MemoryEmployeeStore) PurgeEmployees(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer s.lock()()
+
+	var purged int64
+	for id, employee := range s.employees {
+		if employee.IsDeleted() && employee.DeletedAt.Time.Before(deletedBefore) {
+			delete(s.employees, id)
+			purged++
+		}
+	}
+
+	s.log.WithContext(ctx).WithField("count", purged).Info("Purged deleted employees")
+	return purged, nil
+}
+
+// visible reports whether a read in ctx returns employee.
+func visible(ctx context.Context, employee models.Employee) bool {
+	return !employee.IsDeleted() || includesDeleted(ctx)
+}
+
+// checkVersion is the in-memory equivalent of a conditional write. The
+// caller must hold the lock.
+func (s *MemoryEmployeeStore) checkVersion(id, version int) error {
+	employee, ok := s.employees[id]
+	if !ok || employee.IsDeleted() {
+		return ErrEmployeeNotFound
+	}
+	if employee.Version != version {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+func (s *MemoryEmployeeStore) ListEmployee(ctx context.Context, query ListQuery, offset, limit int) ([]models.Employee, int64, error) {
+	defer s.rlock()()
+
+	matching := s.sorted(ctx, query)
+	employees := []models.Employee{}
+	for i := offset; i < len(matching) && len(employees) < limit; i++ {
+		employees = append(employees, project(matching[i], query.columns()))
+	}
+
+	s.log.WithContext(ctx).WithField("count", len(employees)).Debug("Listed employees")
+	return employees, int64(len(matching)), nil
+}
+
+func (s *MemoryEmployeeStore) ListEmployeesAfter(ctx context.Context, query ListQuery, after *models.Employee, limit int) ([]models.Employee, error) {
+	defer s.rlock()()
+
+	employees := []models.Employee{}
+	for _, employee := range s.sorted(ctx, query) {
+		if len(employees) == limit {
+			break
+		}
+		if after == nil || query.compare(employee, *after) > 0 {
+			employees = append(employees, project(employee, query.columns()))
+		}
+	}
+
+	s.log.WithContext(ctx).WithField("count", len(employees)).Debug("Listed employees")
+	return employees, nil
+}
+
+// sorted returns the employees visible in ctx that match the query's filter,
+// in its order. The caller must hold the lock.
+func (s *MemoryEmployeeStore) sorted(ctx context.Context, query ListQuery) []models.Employee {
+	matching := make([]models.Employee, 0, len(s.employees))
+	for _, employee := range s.employees {
+		if visible(ctx, employee) && query.Filter.matches(employee) {
+			matching = append(matching, employee)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool { return query.compare(matching[i], matching[j]) < 0 })
+	return matching
+}
+
+func (s *MemoryEmployeeStore) CountEmployees(ctx context.Context) (int64, error) {
+	defer s.rlock()()
+
+	var count int64
+	for _, employee := range s.employees {
+		if visible(ctx, employee) {
+			count++
+		}
+	}
+
+	s.log.WithContext(ctx).Debugf("Counted employees: %d", count)
+	return count, nil
+}
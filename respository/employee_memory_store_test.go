@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	loggerNew "golang-assessment/logger"
+	"sync"
+	"testing"
+
+	"golang-assessment/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryEmployeeStore(t *testing.T) {
+	// Setup
+	store := NewMemoryEmployeeStore(loggerNew.Discard())
+
+	t.Run("TestCreateEmployee", func(t *testing.T) {
+		employee := &models.Employee{Name: "John Doe", Position: "Software Engineer", Salary: 50000}
+		store.CreateEmployee(context.Background(), employee)
+		assert.Equal(t, 1, employee.ID)
+	})
+
+	t.Run("TestGetEmployeeByID", func(t *testing.T) {
+		employee, err := store.GetEmployeeByID(context.Background(), 1, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models.Employee{ID: 1, Name: "John Doe", Position: "Software Engineer", Salary: 50000, Version: 1}, withoutTimestamps(employee))
+		assert.False(t, employee.CreatedAt.IsZero())
+
+		_, err = store.GetEmployeeByID(context.Background(), 100, nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestUpdateEmployee", func(t *testing.T) {
+		employee := &models.Employee{ID: 1, Name: "John Doe", Position: "Developer", Salary: 60000, Version: 1}
+		assert.Nil(t, store.UpdateEmployee(context.Background(), employee))
+		assert.Equal(t, 2, employee.Version)
+
+		updated, _ := store.GetEmployeeByID(context.Background(), 1, nil)
+		assert.Equal(t, withoutTimestamps(*employee), withoutTimestamps(updated))
+		assert.Equal(t, employee.UpdatedAt, updated.UpdatedAt)
+		// The caller's CreatedAt is not written
+		assert.False(t, updated.CreatedAt.IsZero())
+
+		stale := &models.Employee{ID: 1, Name: "Stale", Version: 1}
+		assert.ErrorIs(t, store.UpdateEmployee(context.Background(), stale), ErrVersionMismatch)
+
+		assert.NotNil(t, store.UpdateEmployee(context.Background(), &models.Employee{ID: 100}))
+	})
+
+	t.Run("TestListEmployee", func(t *testing.T) {
+		store.CreateEmployee(context.Background(), &models.Employee{Name: "Jane Doe", Position: "Manager", Salary: 70000})
+		store.CreateEmployee(context.Background(), &models.Employee{Name: "Jim Doe", Position: "Tester", Salary: 40000})
+
+		employees, total, err := store.ListEmployee(context.Background(), ListQuery{}, 1, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, employees, 2)
+		assert.Equal(t, 2, employees[0].ID)
+		assert.Equal(t, 3, employees[1].ID)
+
+		employees, _, err = store.ListEmployee(context.Background(), ListQuery{}, 0, 1)
+		assert.Nil(t, err)
+		assert.Len(t, employees, 1)
+		assert.Equal(t, 1, employees[0].ID)
+
+		count, err := store.CountEmployees(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("TestListEmployeesAfter", func(t *testing.T) {
+		employees, err := store.ListEmployeesAfter(context.Background(), ListQuery{}, &models.Employee{ID: 1}, 1)
+		assert.Nil(t, err)
+		assert.Len(t, employees, 1)
+		assert.Equal(t, 2, employees[0].ID)
+
+		employees, err = store.ListEmployeesAfter(context.Background(), ListQuery{}, &models.Employee{ID: 3}, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, employees)
+	})
+
+	t.Run("TestDeleteEmployee", func(t *testing.T) {
+		assert.ErrorIs(t, store.DeleteEmployee(context.Background(), 3, 2, "admin"), ErrVersionMismatch)
+		assert.Nil(t, store.DeleteEmployee(context.Background(), 3, 1, "admin"))
+		assert.NotNil(t, store.DeleteEmployee(context.Background(), 3, 1, "admin"))
+
+		// Deleted IDs are not handed out again
+		employee := &models.Employee{Name: "Joan Doe"}
+		store.CreateEmployee(context.Background(), employee)
+		assert.Equal(t, 4, employee.ID)
+	})
+}
+
+func TestMemoryEmployeeStore_Concurrent(t *testing.T) {
+	store := NewMemoryEmployeeStore(loggerNew.Discard())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			store.CreateEmployee(context.Background(), &models.Employee{Name: "John Doe"})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, _ = store.ListEmployee(context.Background(), ListQuery{}, 0, 10)
+		}()
+	}
+	wg.Wait()
+
+	employees, _, err := store.ListEmployee(context.Background(), ListQuery{}, 0, 100)
+	assert.Nil(t, err)
+	assert.Len(t, employees, 50)
+	for i, employee := range employees {
+		assert.Equal(t, i+1, employee.ID)
+	}
+}
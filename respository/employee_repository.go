@@ -1,89 +1,248 @@
 package repository
 
 import (
-	"fmt"
-	"golang-assessment/logger"
+	"context"
+	"database/sql"
+	"golang-assessment/config"
 	"golang-assessment/models"
-	"sync"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// EmployeeRepository holds no lock of its own: *gorm.DB is safe for
+// concurrent use, and writes that depend on what they read lock the row in
+// the database, which also covers other replicas.
 type EmployeeRepository struct {
-	db *gorm.DB
-	mu sync.Mutex
+	db  *gorm.DB
+	log *logrus.Logger
 }
 
-func NewEmployeeRepository(db *gorm.DB) *EmployeeRepository {
-	return &EmployeeRepository{db: db}
+func NewEmployeeRepository(db *gorm.DB, log *logrus.Logger) *EmployeeRepository {
+	return &EmployeeRepository{db: db, log: log}
 }
 
-func (r *EmployeeRepository) CreateEmployee(employee *models.Employee) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if err := r.db.Create(employee).Error; err != nil {
-		logger.Log.Errorf("Error creating employee: %v", err)
-	} else {
-		logger.Log.Infof("Employee created: %v", employee)
+// WithTx relies on gorm's Transaction, which uses a savepoint when r is
+// already inside a transaction.
+func (r *EmployeeRepository) WithTx(ctx context.Context, fn func(store EmployeeStore) error) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewEmployeeRepository(tx, r.log))
+	}))
+}
+
+func (r *EmployeeRepository) CreateEmployee(ctx context.Context, employee *models.Employee) error {
+	employee.Version = 1
+	if err := r.db.WithContext(ctx).Create(employee).Error; err != nil {
+		r.log.WithContext(ctx).Errorf("Error creating employee: %v", err)
+		return translate(err)
 	}
+
+	r.log.WithContext(ctx).WithField("employee_id", employee.ID).Info("Employee created")
+	return nil
 }
 
-func (r *EmployeeRepository) GetEmployeeByID(id int) (models.Employee, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *EmployeeRepository) GetEmployeeByID(ctx context.Context, id int, fields Fields) (models.Employee, error) {
 	var employee models.Employee
 
-	if err := r.db.First(&employee, id).Error; err != nil {
-		logger.Log.Errorf("Error retreiving employee by ID %d:%v", id, err)
-		return models.Employee{}, err
+	db := scoped(ctx, r.db.WithContext(ctx))
+	if columns := fields.columns(); columns != nil {
+		db = db.Select(columns)
+	}
+	if err := db.First(&employee, id).Error; err != nil {
+		r.log.WithContext(ctx).Errorf("Error retreiving employee by ID %d:%v", id, err)
+		return models.Employee{}, translate(err)
 	}
 
-	logger.Log.Infof("Retrieved employee:%v", employee)
+	r.log.WithContext(ctx).WithField("employee_id", employee.ID).Debug("Retrieved employee")
 	return employee, nil
 }
 
-func (r *EmployeeRepository) UpdateEmployee(employee *models.Employee) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *EmployeeRepository) UpdateEmployee(ctx context.Context, employee *models.Employee) error {
+	now := config.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A map, unlike a struct, also writes zero values
+		return updateVersion(tx, employee.ID, employee.Version, map[string]interface{}{
+			"name":       employee.Name,
+			"position":   employee.Position,
+			"salary":     employee.Salary,
+			"updated_at": now,
+			"updated_by": employee.UpdatedBy,
+		})
+	})
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("Error updating employee :%v", err)
+		return translate(err)
+	}
+	employee.Version++
+	employee.UpdatedAt = now
+
+	r.log.WithContext(ctx).WithField("employee_id", employee.ID).Info("Employee updated")
+	return nil
+}
 
-	if err := r.db.Save(employee).Error; err != nil {
-		logger.Log.Errorf("Error updating employee :%v", err)
-		return err
+func (r *EmployeeRepository) PatchEmployee(ctx context.Context, id, version int, changes EmployeeChanges) (int, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateVersion(tx, id, version, changes.columns())
+	})
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("Error patching employee by ID %d: %v", id, err)
+		return 0, translate(err)
 	}
 
-	logger.Log.Infof("Employee updated : %v", employee)
+	r.log.WithContext(ctx).WithField("employee_id", id).Info("Employee patched")
+	return version + 1, nil
+}
+
+func (r *EmployeeRepository) DeleteEmployee(ctx context.Context, id, version int, actor string) error {
+	now := config.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deleting is a change too, so it also sets updated_at and updated_by
+		return updateVersion(tx, id, version, map[string]interface{}{
+			"deleted_at": now,
+			"deleted_by": actor,
+			"updated_at": now,
+			"updated_by": actor,
+		})
+	})
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("Error deleting employee by ID %d: %v", id, err)
+		return translate(err)
+	}
+
+	r.log.WithContext(ctx).Infof("Employee deleted with ID %d", id)
 	return nil
 }
 
-func (r *EmployeeRepository) DeleteEmployee(id int) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func (r *EmployeeRepository) RestoreEmployee(ctx context.Context, id, version int, actor string) (int, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateVersion(unscoped(tx), id, version, map[string]interface{}{
+			"deleted_at": nil,
+			"deleted_by": "",
+			"updated_at": config.Now(),
+			"updated_by": actor,
+		})
+	})
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("Error restoring employee by ID %d: %v", id, err)
+		return 0, translate(err)
+	}
+
+	r.log.WithContext(ctx).WithField("employee_id", id).Info("Employee restored")
+	return version + 1, nil
+}
 
-	result := r.db.Delete(&models.Employee{}, id)
+func (r *EmployeeRepository) PurgeEmployees(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&models.Employee{})
 	if result.Error != nil {
-		logger.Log.Errorf("Error deleting employee by ID %d: %v", id, result.Error)
-		return result.Error
+		r.log.WithContext(ctx).Errorf("Error purging deleted employees: %v", result.Error)
+		return 0, translate(result.Error)
 	}
-	if result.RowsAffected == 0 {
-		// No rows were affected, indicating that the data with the provided ID is not present
-		return fmt.Errorf("employee with ID %d not found", id)
+
+	r.log.WithContext(ctx).WithField("count", result.RowsAffected).Info("Purged deleted employees")
+	return result.RowsAffected, nil
+}
+
+// unscoped is db seeing soft-deleted rows. Unscoped alone returns a DB that
+// every further statement would add its clauses to; the session lets it be
+// reused like db.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Session(&gorm.Session{})
+}
+
+// scoped is db, or unscoped(db) when ctx asks for soft-deleted rows.
+func scoped(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if includesDeleted(ctx) {
+		return unscoped(db)
 	}
+	return db
+}
 
-	logger.Log.Infof("Employee deleted with ID %d", id)
+// lockVersion locks the employee's row with SELECT ... FOR UPDATE until the
+// transaction ends and checks it is still at the given version. Concurrent
+// writers of the same row queue in the database; writers of other rows are
+// not held up. SQLite has no row locks, but its write transactions are
+// serialized anyway.
+func lockVersion(tx *gorm.DB, id, version int) error {
+	var current models.Employee
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "version").First(&current, id).Error; err != nil {
+		return err
+	}
+	if current.Version != version {
+		return ErrVersionMismatch
+	}
 	return nil
 }
 
-func (r *EmployeeRepository) ListEmployee(offset, limit int) ([]models.Employee, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// updateVersion sets columns and bumps the version of a row that is still at
+// the given version.
+func updateVersion(tx *gorm.DB, id, version int, columns map[string]interface{}) error {
+	if err := lockVersion(tx, id, version); err != nil {
+		return err
+	}
+	columns["version"] = gorm.Expr("version + 1")
+	return tx.Model(&models.Employee{}).Where("id = ?", id).Updates(columns).Error
+}
+
+func (r *EmployeeRepository) ListEmployee(ctx context.Context, query ListQuery, offset, limit int) ([]models.Employee, int64, error) {
+
+	var employee []models.Employee
+	var total int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = scoped(ctx, tx)
+		if err := query.Filter.apply(tx.Model(&models.Employee{})).Count(&total).Error; err != nil {
+			return err
+		}
+		return query.apply(tx).Offset(offset).Limit(limit).Find(&employee).Error
+	}, r.snapshotOptions())
+	if err != nil {
+		r.log.WithContext(ctx).Errorf("Error listing employee: %v", err)
+		return nil, 0, translate(err)
+	}
+
+	r.log.WithContext(ctx).WithField("count", len(employee)).Debug("Listed employees")
+	return employee, total, nil
+}
+
+func (r *EmployeeRepository) ListEmployeesAfter(ctx context.Context, query ListQuery, after *models.Employee, limit int) ([]models.Employee, error) {
 
 	var employee []models.Employee
 
-	if err := r.db.Offset(offset).Limit(limit).Find(&employee).Error; err != nil {
-		logger.Log.Errorf("Error listing employee: %v", err)
-		return nil, err
+	db := query.apply(scoped(ctx, r.db.WithContext(ctx)))
+	if after != nil {
+		db = query.applyAfter(db, after)
+	}
+	if err := db.Limit(limit).Find(&employee).Error; err != nil {
+		r.log.WithContext(ctx).Errorf("Error listing employees: %v", err)
+		return nil, translate(err)
 	}
 
-	logger.Log.Infof("Listed Employees :%v", employee)
+	r.log.WithContext(ctx).WithField("count", len(employee)).Debug("Listed employees")
 	return employee, nil
 }
+
+// snapshotOptions makes every statement of a read-only transaction see the
+// same data, so a page and its total count agree.
+func (r *EmployeeRepository) snapshotOptions() *sql.TxOptions {
+	switch r.db.Dialector.Name() {
+	case config.DialectPostgres, config.DialectMySQL:
+		return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	default:
+		// SQLite transactions are serializable already
+		return nil
+	}
+}
+
+func (r *EmployeeRepository) CountEmployees(ctx context.Context) (int64, error) {
+
+	var count int64
+	if err := scoped(ctx, r.db.WithContext(ctx)).Model(&models.Employee{}).Count(&count).Error; err != nil {
+		r.log.WithContext(ctx).Errorf("Error counting employees: %v", err)
+		return 0, translate(err)
+	}
+
+	r.log.WithContext(ctx).Debugf("Counted employees: %d", count)
+	return count, nil
+}
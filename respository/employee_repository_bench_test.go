@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	loggerNew "golang-assessment/logger"
+	"golang-assessment/models"
+)
+
+// The benchmarks use b.RunParallel, so running them with several -cpu values
+// shows how throughput changes with the number of concurrent callers:
+//
+//	go test -run '^$' -bench EmployeeRepository -cpu 1,2,4,8 ./respository
+//
+// They use a SQLite file rather than an in-memory database, which is limited
+// to one connection.
+
+const benchEmployees = 1000
+
+func setupBenchRepository(b *testing.B) *EmployeeRepository {
+	db := openTestDB(b, filepath.Join(b.TempDir(), "bench.db"))
+	sqlDB, err := db.DB()
+	if err != nil {
+		b.Fatalf("error getting connection pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(16)
+	sqlDB.SetMaxIdleConns(16)
+
+	repo := NewEmployeeRepository(db, loggerNew.Discard())
+	employees := make([]models.Employee, benchEmployees)
+	for i := range employees {
+		employees[i] = models.Employee{Name: fmt.Sprintf("Employee %d", i), Position: "Developer", Salary: float64(i), Version: 1}
+	}
+	if err := db.CreateInBatches(employees, 100).Error; err != nil {
+		b.Fatalf("error seeding employees: %v", err)
+	}
+	b.ResetTimer()
+	return repo
+}
+
+func BenchmarkEmployeeRepository_GetEmployeeByID(b *testing.B) {
+	repo := setupBenchRepository(b)
+	b.RunParallel(func(pb *testing.PB) {
+		id := 0
+		for pb.Next() {
+			id = id%benchEmployees + 1
+			if _, err := repo.GetEmployeeByID(context.Background(), id, nil); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEmployeeRepository_ListEmployee(b *testing.B) {
+	repo := setupBenchRepository(b)
+	query := ListQuery{Sort: []SortKey{{Field: "salary", Desc: true}}}
+	b.RunParallel(func(pb *testing.PB) {
+		offset := 0
+		for pb.Next() {
+			offset = (offset + 20) % benchEmployees
+			if _, _, err := repo.ListEmployee(context.Background(), query, offset, 20); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEmployeeRepository_ListEmployeesAfter(b *testing.B) {
+	repo := setupBenchRepository(b)
+	query := ListQuery{Sort: []SortKey{{Field: "name"}}}
+	after := &models.Employee{ID: benchEmployees / 2, Name: fmt.Sprintf("Employee %d", benchEmployees/2)}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := repo.ListEmployeesAfter(context.Background(), query, after, 20); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+// BenchmarkEmployeeRepository_AuditedWrites patches a different employee from
+// every goroutine, so the row locks never conflict. Comparing the two cases
+// across -cpu values shows what the global audit append lock costs. SQLite
+// lets one write transaction in at a time, so the pool is cut to a single
+// connection and the difference here is only the extra statements; on
+// Postgres or MySQL the audited case stops scaling with -cpu.
+func BenchmarkEmployeeRepository_AuditedWrites(b *testing.B) {
+	for _, audited := range []bool{false, true} {
+		name := "unaudited"
+		if audited {
+			name = "audited"
+		}
+		b.Run(name, func(b *testing.B) {
+			repo := setupBenchRepository(b)
+			sqlDB, err := repo.db.DB()
+			if err != nil {
+				b.Fatalf("error getting connection pool: %v", err)
+			}
+			sqlDB.SetMaxOpenConns(1)
+			var next atomic.Int64
+			b.RunParallel(func(pb *testing.PB) {
+				id, version := int(next.Add(1)), 1
+				position := "Lead"
+				for pb.Next() {
+					err := repo.WithTx(context.Background(), func(store EmployeeStore) error {
+						var err error
+						if version, err = store.PatchEmployee(context.Background(), id, version, EmployeeChanges{Position: &position}); err != nil || !audited {
+							return err
+						}
+						return store.AppendAudit(context.Background(), &models.AuditEntry{EmployeeID: id, Operation: models.AuditUpdate, Changes: models.AuditChanges{}})
+					})
+					if err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -1,66 +1,263 @@
 package repository
 
 import (
+	"context"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"golang-assessment/apperrors"
+	"golang-assessment/config"
 	loggerNew "golang-assessment/logger"
+	"golang-assessment/migrations"
 	"golang-assessment/models"
 
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-func setupTestDB(t *testing.T) *gorm.DB {
-	// Connect to your test database
-	dsn := "host=localhost user=postgres password=root123 dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Shanghai"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+func setupTestDB(t testing.TB) *gorm.DB {
+	// Connect to an in-memory SQLite test database
+	return openTestDB(t, config.SQLiteInMemory)
+}
+
+// openTestDB opens and migrates a SQLite database. An in-memory database has
+// a single connection; a file allows concurrent readers.
+func openTestDB(t testing.TB, path string) *gorm.DB {
+	db, err := config.OpenDatabase(&config.DatabaseConfig{Dialect: config.DialectSQLite, Path: path})
 	if err != nil {
 		t.Fatalf("error opening database: %v", err)
 	}
+	migrator, err := migrations.NewMigrator(db, config.DialectSQLite, loggerNew.Discard())
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("error migrating database: %v", err)
+	}
 	return db
 }
-func setupTestLogger(t *testing.T) {
-	// Initialize a buffer to capture log output
-	logger := logrus.New()
-	// Replace the global logger with the mock logger
-	loggerNew.Log = logger
+
+// sqlRecorder collects the statements GORM builds.
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	if !strings.HasPrefix(sql, "SAVEPOINT") && !strings.HasPrefix(sql, "RELEASE SAVEPOINT") {
+		r.statements = append(r.statements, sql)
+	}
+}
+
+// dryRunConn stands in for a Postgres connection. A dry run sends nothing,
+// and as a TxCommitter every transaction becomes a savepoint on it.
+type dryRunConn struct {
+	gorm.ConnPool
+}
+
+func (dryRunConn) Commit() error   { return nil }
+func (dryRunConn) Rollback() error { return nil }
+
+// dryRunPostgres opens a database that records the Postgres SQL it would run,
+// for checking clauses the SQLite driver leaves out.
+func dryRunPostgres(t testing.TB) (*gorm.DB, *sqlRecorder) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunConn{}}), &gorm.Config{DryRun: true, Logger: recorder})
+	if err != nil {
+		t.Fatalf("error opening dry-run database: %v", err)
+	}
+	return db, recorder
+}
+
+// withoutTimestamps zeroes the times a store sets, for comparing employees.
+func withoutTimestamps(employee models.Employee) models.Employee {
+	employee.CreatedAt = time.Time{}
+	employee.UpdatedAt = time.Time{}
+	return employee
 }
 
 func TestEmployeeRepository(t *testing.T) {
 	// Setup
-	setupTestLogger(t)
 	db := setupTestDB(t)
-	repo := NewEmployeeRepository(db)
+	repo := NewEmployeeRepository(db, loggerNew.Discard())
 
 	t.Run("TestCreateEmployee", func(t *testing.T) {
 		// Test CreateEmployee function
 		employee := &models.Employee{Name: "John Doe", Position: "Software Engineer", Salary: 50000}
-		repo.CreateEmployee(employee)
+		assert.Nil(t, repo.CreateEmployee(context.Background(), employee))
+		assert.Equal(t, 1, employee.ID)
+
+		// The primary key is taken, which the driver reports as a unique violation
+		duplicate := &models.Employee{ID: 1, Name: "Jane Doe"}
+		err := repo.CreateEmployee(context.Background(), duplicate)
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
 	})
 
 	t.Run("TestGetEmployeeByID", func(t *testing.T) {
 		// Test GetEmployeeByID function
-		id := 11
-		employee, err := repo.GetEmployeeByID(id)
-		expectedResponse := models.Employee(models.Employee{ID: 11, Name: "John Doe", Position: "Developer", Salary: 60000})
+		id := 1
+		employee, err := repo.GetEmployeeByID(context.Background(), id, nil)
+		expectedResponse := models.Employee(models.Employee{ID: 1, Name: "John Doe", Position: "Software Engineer", Salary: 50000, Version: 1})
 		assert.Nil(t, err)
-		assert.Equal(t, employee, expectedResponse)
+		assert.Equal(t, withoutTimestamps(employee), expectedResponse)
+		assert.False(t, employee.CreatedAt.IsZero())
+		assert.Equal(t, employee.CreatedAt, employee.UpdatedAt)
 	})
 
 	t.Run("TestUpdateEmployee", func(t *testing.T) {
 		// Test UpdateEmployee function
-		employee := &models.Employee{ID: 11, Name: "John Doe", Position: "Developer", Salary: 60000}
-		err := repo.UpdateEmployee(employee)
+		employee := &models.Employee{ID: 1, Name: "John Doe", Position: "Developer", Salary: 60000, Version: 1}
+		err := repo.UpdateEmployee(context.Background(), employee)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, employee.Version)
+
+		updated, err := repo.GetEmployeeByID(context.Background(), 1, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, withoutTimestamps(*employee), withoutTimestamps(updated))
+		assert.Equal(t, employee.UpdatedAt, updated.UpdatedAt)
+		assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+
+		// A write based on the old version must not apply
+		stale := &models.Employee{ID: 1, Name: "Stale", Version: 1}
+		assert.ErrorIs(t, repo.UpdateEmployee(context.Background(), stale), ErrVersionMismatch)
+		assert.ErrorIs(t, repo.UpdateEmployee(context.Background(), &models.Employee{ID: 100, Version: 1}), apperrors.ErrNotFound)
+	})
+
+	t.Run("TestListEmployee", func(t *testing.T) {
+		// Test ListEmployee function
+		repo.CreateEmployee(context.Background(), &models.Employee{Name: "Jane Doe", Position: "Manager", Salary: 70000})
+		employees, total, err := repo.ListEmployee(context.Background(), ListQuery{}, 1, 10)
 		assert.Nil(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, employees, 1)
+		assert.Equal(t, 2, employees[0].ID)
+
+		count, err := repo.CountEmployees(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("TestListEmployeesAfter", func(t *testing.T) {
+		employees, err := repo.ListEmployeesAfter(context.Background(), ListQuery{}, nil, 1)
+		assert.Nil(t, err)
+		assert.Len(t, employees, 1)
+		assert.Equal(t, 1, employees[0].ID)
+
+		employees, err = repo.ListEmployeesAfter(context.Background(), ListQuery{}, &models.Employee{ID: 1}, 10)
+		assert.Nil(t, err)
+		assert.Len(t, employees, 1)
+		assert.Equal(t, 2, employees[0].ID)
 	})
 
 	t.Run("TestDeleteEmployee", func(t *testing.T) {
 		// Test DeleteEmployee function
-		id := 11
-		err := repo.DeleteEmployee(id)
+		id := 1
+		err := repo.DeleteEmployee(context.Background(), id, 1, "admin")
+		assert.ErrorIs(t, err, ErrVersionMismatch)
+
+		err = repo.DeleteEmployee(context.Background(), id, 2, "admin")
 		assert.Nil(t, err)
+
+		err = repo.DeleteEmployee(context.Background(), id, 2, "admin")
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
 	})
 
 }
+
+func TestEmployeeRepository_ConcurrentUpdates(t *testing.T) {
+	// Setup
+	repo := NewEmployeeRepository(setupTestDB(t), loggerNew.Discard())
+	repo.CreateEmployee(context.Background(), &models.Employee{Name: "John Doe", Position: "Developer", Salary: 50000})
+
+	// Every writer read version 1, so exactly one of them may win. SQLite
+	// runs them one at a time; TestEmployeeRepository_WritesLockTheRow
+	// covers the row lock other databases need.
+	const writers = 8
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- repo.UpdateEmployee(context.Background(), &models.Employee{ID: 1, Name: "John Doe", Position: "Developer", Salary: float64(i), Version: 1})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrVersionMismatch)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	employee, err := repo.GetEmployeeByID(context.Background(), 1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, employee.Version)
+}
+
+func TestEmployeeRepository_AuditIsImmutable(t *testing.T) {
+	// Setup
+	db := setupTestDB(t)
+	repo := NewEmployeeRepository(db, loggerNew.Discard())
+	assert.Nil(t, repo.AppendAudit(context.Background(), &models.AuditEntry{EmployeeID: 1, Operation: models.AuditCreate}))
+
+	// Not even a direct statement may rewrite history
+	assert.ErrorContains(t, db.Exec("UPDATE audit_entries SET actor = 'someone else'").Error, "audit entries are immutable")
+	assert.ErrorContains(t, db.Exec("DELETE FROM audit_entries").Error, "audit entries are immutable")
+
+	entries, err := repo.ListAudit(context.Background(), AuditQuery{}, 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Empty(t, entries[0].Actor)
+}
+
+func TestEmployeeRepository_AuditAppendsSerialized(t *testing.T) {
+	// Setup
+	db, recorder := dryRunPostgres(t)
+	repo := NewEmployeeRepository(db, loggerNew.Discard())
+
+	// The lock is taken before the entry gets its ID and held to the commit
+	assert.Nil(t, repo.AppendAudit(context.Background(), &models.AuditEntry{EmployeeID: 1, Operation: models.AuditCreate}))
+	assert.Len(t, recorder.statements, 2)
+	assert.Contains(t, recorder.statements[0], `FROM "audit_append_lock"`)
+	assert.Contains(t, recorder.statements[0], "FOR UPDATE")
+	assert.Contains(t, recorder.statements[1], `INSERT INTO "audit_entries"`)
+}
+
+func TestEmployeeRepository_WritesLockTheRow(t *testing.T) {
+	// Setup
+	db, recorder := dryRunPostgres(t)
+	repo := NewEmployeeRepository(db, loggerNew.Discard())
+	ctx := context.Background()
+	name := "Jane Doe"
+
+	// SQLite drops FOR UPDATE and serializes writers anyway, so the lock
+	// that makes the version check safe is only visible in the SQL
+	for method, write := range map[string]func() error{
+		"UpdateEmployee": func() error {
+			return repo.UpdateEmployee(ctx, &models.Employee{ID: 1, Name: "John Doe", Position: "Developer", Salary: 50000})
+		},
+		"PatchEmployee": func() error {
+			_, err := repo.PatchEmployee(ctx, 1, 0, EmployeeChanges{Name: &name})
+			return err
+		},
+		"DeleteEmployee": func() error { return repo.DeleteEmployee(ctx, 1, 0, "ada") },
+	} {
+		recorder.statements = nil
+		assert.Nil(t, write(), method)
+		assert.Len(t, recorder.statements, 2, method)
+		assert.Regexp(t, `^SELECT "id","version" FROM "employees" WHERE .* FOR UPDATE$`, recorder.statements[0], method)
+		assert.Contains(t, recorder.statements[1], `UPDATE "employees" SET`, method)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"golang-assessment/config"
+	"golang-assessment/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// UnitOfWork groups store calls into one transaction.
+type UnitOfWork interface {
+	// WithTx runs fn with a store whose calls all belong to one transaction,
+	// committed when fn returns nil and rolled back when it returns an error
+	// or panics (the panic is re-raised). Called on the store of an enclosing
+	// WithTx it sets a savepoint, so a failing fn only undoes its own changes.
+	WithTx(ctx context.Context, fn func(store EmployeeStore) error) error
+}
+
+// EmployeeStore is the storage contract the service layer depends on.
+// EmployeeRepository (GORM) and MemoryEmployeeStore both implement it. Errors
+// are of the apperrors kinds; a missing employee is ErrEmployeeNotFound.
+// Deletes are soft: reads and writes treat a deleted employee as missing,
+// unless the read's context comes from IncludeDeleted. The audit log lives in
+// the same store, so changes and their entries share a transaction.
+//
+// Audited writes are serialized: AppendAudit takes one lock shared by every
+// writer in every process and holds it until the transaction commits, so
+// writes to different employees commit one at a time from that point on. The
+// row locks of UpdateEmployee and the rest only keep the work before the
+// append concurrent, which is why the service appends last.
+// BenchmarkEmployeeRepository_AuditedWrites measures the cost.
+type EmployeeStore interface {
+	UnitOfWork
+	AuditStore
+	CreateEmployee(ctx context.Context, employee *models.Employee) error
+	GetEmployeeByID(ctx context.Context, id int, fields Fields) (models.Employee, error)
+	// UpdateEmployee, PatchEmployee and DeleteEmployee only apply to the
+	// given version of the employee and return ErrVersionMismatch otherwise,
+	// which holds across processes sharing the database. Writes bump the
+	// version and UpdatedAt; UpdateEmployee sets both on employee. CreatedAt
+	// and CreatedBy never change.
+	UpdateEmployee(ctx context.Context, employee *models.Employee) error
+	// PatchEmployee writes only the changed columns and returns the new
+	// version.
+	PatchEmployee(ctx context.Context, id, version int, changes EmployeeChanges) (int, error)
+	// DeleteEmployee soft-deletes the employee, recording actor as
+	// DeletedBy.
+	DeleteEmployee(ctx context.Context, id, version int, actor string) error
+	// RestoreEmployee undoes a soft delete, recording actor as UpdatedBy, and
+	// returns the new version.
+	RestoreEmployee(ctx context.Context, id, version int, actor string) (int, error)
+	// PurgeEmployees removes the employees soft-deleted before deletedBefore
+	// for good and returns how many there were.
+	PurgeEmployees(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// ListEmployee returns one page of the employees matching the query and
+	// their total number, both read from the same snapshot.
+	ListEmployee(ctx context.Context, query ListQuery, offset, limit int) ([]models.Employee, int64, error)
+	// ListEmployeesAfter returns up to limit matching employees that come
+	// after the given one in the query's order, or from the start when after
+	// is nil. Unlike offsets, the position stays correct when rows are
+	// inserted or deleted between calls.
+	ListEmployeesAfter(ctx context.Context, query ListQuery, after *models.Employee, limit int) ([]models.Employee, error)
+	CountEmployees(ctx context.Context) (int64, error)
+}
+
+var (
+	_ EmployeeStore = (*EmployeeRepository)(nil)
+	_ EmployeeStore = (*MemoryEmployeeStore)(nil)
+)
+
+// NewEmployeeStore picks the backend for the configured dialect. The "memory"
+// dialect needs no database, so db may be nil in that case.
+func NewEmployeeStore(dialect string, db *gorm.DB, log *logrus.Logger) EmployeeStore {
+	if dialect == config.DialectMemory {
+		return NewMemoryEmployeeStore(log)
+	}
+	return NewEmployeeRepository(db, log)
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-assessment/apperrors"
+	"golang-assessment/config"
+	"golang-assessment/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	count := func(t *testing.T, store EmployeeStore) int64 {
+		count, err := store.CountEmployees(ctx)
+		assert.Nil(t, err)
+		return count
+	}
+
+	for name, store := range setupListStores(t) {
+		t.Run(name+"/commit", func(t *testing.T) {
+			err := store.WithTx(ctx, func(tx EmployeeStore) error {
+				tx.CreateEmployee(ctx, &models.Employee{Name: "Committed", Position: "Developer", Salary: 1})
+				// The transaction sees its own writes
+				assert.Equal(t, int64(6), count(t, tx))
+				return nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(6), count(t, store))
+		})
+
+		t.Run(name+"/rollback on error", func(t *testing.T) {
+			err := store.WithTx(ctx, func(tx EmployeeStore) error {
+				tx.CreateEmployee(ctx, &models.Employee{Name: "Rolled back", Position: "Developer", Salary: 1})
+				return errFailed
+			})
+			assert.ErrorIs(t, err, errFailed)
+			assert.Equal(t, int64(6), count(t, store))
+		})
+
+		t.Run(name+"/rollback on panic", func(t *testing.T) {
+			assert.PanicsWithValue(t, "boom", func() {
+				store.WithTx(ctx, func(tx EmployeeStore) error {
+					assert.Nil(t, tx.DeleteEmployee(ctx, 1, 1, "admin"))
+					panic("boom")
+				})
+			})
+			_, err := store.GetEmployeeByID(ctx, 1, nil)
+			assert.Nil(t, err)
+		})
+
+		t.Run(name+"/savepoint", func(t *testing.T) {
+			err := store.WithTx(ctx, func(tx EmployeeStore) error {
+				tx.CreateEmployee(ctx, &models.Employee{Name: "Outer", Position: "Developer", Salary: 1})
+				err := tx.WithTx(ctx, func(nested EmployeeStore) error {
+					nested.CreateEmployee(ctx, &models.Employee{Name: "Inner", Position: "Developer", Salary: 1})
+					return errFailed
+				})
+				assert.ErrorIs(t, err, errFailed)
+				return nil
+			})
+			assert.Nil(t, err)
+
+			employees, _, err := store.ListEmployee(ctx, ListQuery{Filter: EmployeeFilter{NameContains: "er"}}, 0, 10)
+			assert.Nil(t, err)
+			var names []string
+			for _, employee := range employees {
+				names = append(names, employee.Name)
+			}
+			assert.Equal(t, []string{"Outer"}, names)
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range setupListStores(t) {
+		t.Run(name+"/deleted employees are hidden", func(t *testing.T) {
+			assert.Nil(t, store.DeleteEmployee(ctx, 1, 1, "hr"))
+
+			_, err := store.GetEmployeeByID(ctx, 1, nil)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			employees, total, err := store.ListEmployee(ctx, ListQuery{}, 0, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []int{2, 3, 4, 5}, ids(employees))
+			assert.Equal(t, int64(4), total)
+			count, _ := store.CountEmployees(ctx)
+			assert.Equal(t, int64(4), count)
+			// Nor can they be changed
+			assert.ErrorIs(t, store.UpdateEmployee(ctx, &models.Employee{ID: 1, Name: "John Doe", Version: 2}), apperrors.ErrNotFound)
+			assert.ErrorIs(t, store.DeleteEmployee(ctx, 1, 2, "hr"), apperrors.ErrNotFound)
+		})
+
+		t.Run(name+"/include deleted", func(t *testing.T) {
+			deleted, err := store.GetEmployeeByID(IncludeDeleted(ctx), 1, nil)
+			assert.Nil(t, err)
+			assert.True(t, deleted.IsDeleted())
+			assert.Equal(t, "hr", deleted.DeletedBy)
+			assert.Equal(t, 2, deleted.Version)
+			// Both backends record the delete as the latest update
+			assert.Equal(t, "hr", deleted.UpdatedBy)
+			assert.True(t, deleted.UpdatedAt.Equal(deleted.DeletedAt.Time), "updated_at %v, deleted_at %v", deleted.UpdatedAt, deleted.DeletedAt.Time)
+
+			employees, total, err := store.ListEmployee(IncludeDeleted(ctx), ListQuery{}, 0, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []int{1, 2, 3, 4, 5}, ids(employees))
+			assert.Equal(t, int64(5), total)
+			employees, err = store.ListEmployeesAfter(IncludeDeleted(ctx), ListQuery{}, nil, 10)
+			assert.Nil(t, err)
+			assert.Len(t, employees, 5)
+		})
+
+		t.Run(name+"/restore", func(t *testing.T) {
+			_, err := store.RestoreEmployee(ctx, 1, 1, "admin")
+			assert.ErrorIs(t, err, ErrVersionMismatch)
+
+			version, err := store.RestoreEmployee(ctx, 1, 2, "admin")
+			assert.Nil(t, err)
+			assert.Equal(t, 3, version)
+			restored, err := store.GetEmployeeByID(ctx, 1, nil)
+			assert.Nil(t, err)
+			assert.False(t, restored.IsDeleted())
+			assert.Empty(t, restored.DeletedBy)
+			assert.Equal(t, "admin", restored.UpdatedBy)
+		})
+
+		t.Run(name+"/purge", func(t *testing.T) {
+			assert.Nil(t, store.DeleteEmployee(ctx, 2, 1, "hr"))
+
+			purged, err := store.PurgeEmployees(ctx, config.Now().Add(-time.Hour))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), purged)
+
+			purged, err = store.PurgeEmployees(ctx, config.Now().Add(time.Second))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), purged)
+			_, err = store.GetEmployeeByID(IncludeDeleted(ctx), 2, nil)
+			assert.ErrorIs(t, err, apperrors.ErrNotFound)
+			// Employees that are not deleted are never purged
+			count, _ := store.CountEmployees(IncludeDeleted(ctx))
+			assert.Equal(t, int64(4), count)
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	entryIDs := func(entries []models.AuditEntry) []int64 {
+		result := make([]int64, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.ID
+		}
+		return result
+	}
+
+	for name, store := range setupListStores(t) {
+		var start time.Time
+
+		t.Run(name+"/append", func(t *testing.T) {
+			start = config.Now()
+			for _, employeeID := range []int{1, 2, 1} {
+				entry := models.AuditEntry{
+					EmployeeID: employeeID,
+					Operation:  models.AuditUpdate,
+					Actor:      "hr",
+					RequestID:  "req-1",
+					Changes:    models.AuditChanges{{Field: "salary", Before: float64(60000), After: float64(65000)}},
+				}
+				assert.Nil(t, store.AppendAudit(ctx, &entry))
+				assert.NotZero(t, entry.ID)
+				assert.False(t, entry.Timestamp.Before(start))
+			}
+
+			entries, err := store.ListAudit(ctx, AuditQuery{}, 0, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []int64{1, 2, 3}, entryIDs(entries))
+			assert.Equal(t, "req-1", entries[0].RequestID)
+			assert.Equal(t, models.AuditChanges{{Field: "salary", Before: float64(60000), After: float64(65000)}}, entries[0].Changes)
+		})
+
+		t.Run(name+"/filters and cursor", func(t *testing.T) {
+			entries, err := store.ListAudit(ctx, AuditQuery{EmployeeID: 1}, 0, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []int64{1, 3}, entryIDs(entries))
+
+			entries, err = store.ListAudit(ctx, AuditQuery{}, 1, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, []int64{2}, entryIDs(entries))
+
+			entries, err = store.ListAudit(ctx, AuditQuery{Since: start}, 0, 10)
+			assert.Nil(t, err)
+			assert.Len(t, entries, 3)
+			entries, err = store.ListAudit(ctx, AuditQuery{Since: config.Now().Add(time.Hour)}, 0, 10)
+			assert.Nil(t, err)
+			assert.Empty(t, entries)
+		})
+
+		t.Run(name+"/rolls back with its transaction", func(t *testing.T) {
+			err := store.WithTx(ctx, func(tx EmployeeStore) error {
+				assert.Nil(t, tx.AppendAudit(ctx, &models.AuditEntry{EmployeeID: 3, Operation: models.AuditDelete}))
+				return errFailed
+			})
+			assert.ErrorIs(t, err, errFailed)
+
+			entries, err := store.ListAudit(ctx, AuditQuery{EmployeeID: 3}, 0, 10)
+			assert.Nil(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"golang-assessment/apperrors"
+	"net"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrVersionMismatch means the employee was changed since the version a
+	// write was based on.
+	ErrVersionMismatch = apperrors.New(apperrors.ErrConflict, "employee was modified by another request")
+	// ErrEmployeeNotFound means no employee has the requested ID.
+	ErrEmployeeNotFound = apperrors.New(apperrors.ErrNotFound, "employee not found")
+)
+
+// translate turns a GORM or driver error into one of the apperrors kinds.
+// It relies on gorm.Config.TranslateError, which maps each dialect's
+// constraint violations to gorm's own errors. Errors it does not recognise
+// are returned as they are and end up as internal errors.
+func translate(err error) error {
+	var appErr *apperrors.Error
+	var netErr net.Error
+	switch {
+	case err == nil, errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &apperrors.Error{Kind: apperrors.ErrNotFound, Message: ErrEmployeeNotFound.Error(), Err: err}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &apperrors.Error{Kind: apperrors.ErrConflict, Message: "employee already exists", Err: err}
+	case errors.Is(err, gorm.ErrForeignKeyViolated), errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return apperrors.Wrap(apperrors.ErrValidation, err)
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return apperrors.Wrap(apperrors.ErrUnavailable, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+
+	"golang-assessment/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTranslate(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	other := errors.New("syntax error")
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"NotFound", gorm.ErrRecordNotFound, apperrors.ErrNotFound},
+		{"DuplicatedKey", gorm.ErrDuplicatedKey, apperrors.ErrConflict},
+		{"ForeignKey", gorm.ErrForeignKeyViolated, apperrors.ErrValidation},
+		{"CheckConstraint", gorm.ErrCheckConstraintViolated, apperrors.ErrValidation},
+		{"BadConn", driver.ErrBadConn, apperrors.ErrUnavailable},
+		{"ConnDone", sql.ErrConnDone, apperrors.ErrUnavailable},
+		{"Deadline", context.DeadlineExceeded, apperrors.ErrUnavailable},
+		{"Dial", dialErr, apperrors.ErrUnavailable},
+		{"AlreadyTranslated", ErrVersionMismatch, apperrors.ErrConflict},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := translate(tc.err)
+			assert.ErrorIs(t, err, tc.kind)
+			// The cause stays in the chain for logs and tracing
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		assert.Equal(t, other, translate(other))
+		assert.Nil(t, translate(nil))
+	})
+
+	t.Run("Message", func(t *testing.T) {
+		assert.EqualError(t, translate(gorm.ErrRecordNotFound), "employee not found")
+		assert.EqualError(t, translate(driver.ErrBadConn), driver.ErrBadConn.Error())
+	})
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+	"golang-assessment/models"
+	"sort"
+	"strings"
+)
+
+// fieldColumns maps the JSON fields of an employee to their columns.
+var fieldColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"position":   "position",
+	"salary":     "salary",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"created_by": "created_by",
+	"updated_by": "updated_by",
+	"deleted_at": "deleted_at",
+	"deleted_by": "deleted_by",
+}
+
+// Fields is a sparse fieldset: the JSON fields of an employee a read should
+// return. An empty Fields selects every field.
+type Fields []string
+
+// ParseFields parses a comma separated list of field names, e.g.
+// "id,name,position".
+func ParseFields(raw string) (Fields, error) {
+	var fields Fields
+	seen := map[string]bool{}
+	for _, item := range strings.Split(raw, ",") {
+		field := strings.TrimSpace(item)
+		if field == "" {
+			continue
+		}
+		if _, ok := fieldColumns[field]; !ok {
+			names := make([]string, 0, len(fieldColumns))
+			for name := range fieldColumns {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown field %q, selectable fields are %s", field, strings.Join(names, ", "))
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("%q appears more than once", field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// columns lists the columns to select: the fields plus id, version (for the
+// ETag) and any extra fields the store needs itself, such as sort keys. It is nil, meaning every
+// column, when no fields were asked for.
+func (f Fields) columns(extra ...string) []string {
+	if len(f) == 0 {
+		return nil
+	}
+	columns := []string{"id", "version"}
+	seen := map[string]bool{"id": true}
+	for _, field := range append(append([]string{}, f...), extra...) {
+		if !seen[field] {
+			seen[field] = true
+			columns = append(columns, fieldColumns[field])
+		}
+	}
+	return columns
+}
+
+// project is the in-memory equivalent of selecting only the given columns:
+// every other field is left at its zero value.
+func project(employee models.Employee, columns []string) models.Employee {
+	if columns == nil {
+		return employee
+	}
+	projected := models.Employee{}
+	for _, column := range columns {
+		switch column {
+		case "id":
+			projected.ID = employee.ID
+		case "version":
+			projected.Version = employee.Version
+		case "name":
+			projected.Name = employee.Name
+		case "position":
+			projected.Position = employee.Position
+		case "salary":
+			projected.Salary = employee.Salary
+		case "created_at":
+			projected.CreatedAt = employee.CreatedAt
+		case "updated_at":
+			projected.UpdatedAt = employee.UpdatedAt
+		case "created_by":
+			projected.CreatedBy = employee.CreatedBy
+		case "updated_by":
+			projected.UpdatedBy = employee.UpdatedBy
+		case "deleted_at":
+			projected.DeletedAt = employee.DeletedAt
+		case "deleted_by":
+			projected.DeletedBy = employee.DeletedBy
+		}
+	}
+	return projected
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"fmt"
+	"golang-assessment/models"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// sortColumns whitelists the fields a list can be sorted by. Each has an
+// index on (column, id); see migration 0002.
+var sortColumns = map[string]string{
+	"id":       "id",
+	"name":     "name",
+	"position": "position",
+	"salary":   "salary",
+}
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a comma separated list of fields, each optionally
+// prefixed with "-" for descending order, e.g. "-salary,name".
+func ParseSort(raw string) ([]SortKey, error) {
+	var keys []SortKey
+	seen := map[string]bool{}
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key := SortKey{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if _, ok := sortColumns[key.Field]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q, sortable fields are %s", key.Field, strings.Join(sortableFields(), ", "))
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%q appears more than once", key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func sortableFields() []string {
+	fields := make([]string, 0, len(sortColumns))
+	for field := range sortColumns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// EmployeeFilter narrows a list. Zero values do not filter.
+type EmployeeFilter struct {
+	Position string
+	// PositionPrefix and NameContains match case-insensitively.
+	PositionPrefix string
+	NameContains   string
+	SalaryGTE      *float64
+	SalaryLTE      *float64
+}
+
+// ListQuery selects and orders the employees of a list. Fields does not
+// change which employees are listed, only which of their fields are read.
+type ListQuery struct {
+	Filter EmployeeFilter
+	Sort   []SortKey
+	Fields Fields
+}
+
+// keys is the full ordering: Sort followed by ID ascending, unless Sort
+// already ends the tie-breaking with ID.
+func (q ListQuery) keys() []SortKey {
+	keys := append([]SortKey{}, q.Sort...)
+	for _, key := range keys {
+		if key.Field == "id" {
+			return keys
+		}
+	}
+	return append(keys, SortKey{Field: "id"})
+}
+
+// columns is what to select for the query's fields. The sort keys are always
+// read, so a cursor can be made from the last row.
+func (q ListQuery) columns() []string {
+	var extra []string
+	for _, key := range q.keys() {
+		extra = append(extra, key.Field)
+	}
+	return q.Fields.columns(extra...)
+}
+
+// String is a canonical form of the query, so a cursor can tell whether it
+// is being used with the query it was issued for.
+func (q ListQuery) String() string {
+	values := url.Values{}
+	f := q.Filter
+	if f.Position != "" {
+		values.Set("position", f.Position)
+	}
+	if f.PositionPrefix != "" {
+		values.Set("position_prefix", strings.ToLower(f.PositionPrefix))
+	}
+	if f.NameContains != "" {
+		values.Set("name_contains", strings.ToLower(f.NameContains))
+	}
+	if f.SalaryGTE != nil {
+		values.Set("salary_gte", strconv.FormatFloat(*f.SalaryGTE, 'g', -1, 64))
+	}
+	if f.SalaryLTE != nil {
+		values.Set("salary_lte", strconv.FormatFloat(*f.SalaryLTE, 'g', -1, 64))
+	}
+	values.Set("sort", q.SortString())
+	return values.Encode()
+}
+
+// FilterNames lists the filters the query uses without their values, which
+// are employee data, for where the query is recorded.
+func (q ListQuery) FilterNames() []string {
+	f := q.Filter
+	names := []string{}
+	for _, filter := range []struct {
+		name string
+		set  bool
+	}{
+		{"position", f.Position != ""},
+		{"position_prefix", f.PositionPrefix != ""},
+		{"name_contains", f.NameContains != ""},
+		{"salary_gte", f.SalaryGTE != nil},
+		{"salary_lte", f.SalaryLTE != nil},
+	} {
+		if filter.set {
+			names = append(names, filter.name)
+		}
+	}
+	return names
+}
+
+// SortString is the full ordering in the form of the sort parameter.
+func (q ListQuery) SortString() string {
+	var parts []string
+	for _, key := range q.keys() {
+		if key.Desc {
+			parts = append(parts, "-"+key.Field)
+		} else {
+			parts = append(parts, key.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// KeyOf returns the employee's values for every sort key, to resume a list
+// after it.
+func (q ListQuery) KeyOf(employee models.Employee) []interface{} {
+	keys := q.keys()
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = sortValue(employee, key.Field)
+	}
+	return values
+}
+
+// After rebuilds the position KeyOf described. The values may have been
+// through JSON, so numbers can arrive as any numeric type.
+func (q ListQuery) After(values []interface{}) (*models.Employee, error) {
+	keys := q.keys()
+	if len(values) != len(keys) {
+		return nil, fmt.Errorf("expected %d key values, got %d", len(keys), len(values))
+	}
+	employee := &models.Employee{}
+	for i, key := range keys {
+		var ok bool
+		switch key.Field {
+		case "id":
+			var id float64
+			id, ok = number(values[i])
+			employee.ID = int(id)
+		case "name":
+			employee.Name, ok = values[i].(string)
+		case "position":
+			employee.Position, ok = values[i].(string)
+		case "salary":
+			employee.Salary, ok = number(values[i])
+		}
+		if !ok {
+			return nil, fmt.Errorf("bad value for %s", key.Field)
+		}
+	}
+	return employee, nil
+}
+
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func sortValue(employee models.Employee, field string) interface{} {
+	switch field {
+	case "name":
+		return employee.Name
+	case "position":
+		return employee.Position
+	case "salary":
+		return employee.Salary
+	default:
+		return employee.ID
+	}
+}
+
+// apply adds the filters and ordering to a GORM query.
+func (q ListQuery) apply(db *gorm.DB) *gorm.DB {
+	db = q.Filter.apply(db)
+	if columns := q.columns(); columns != nil {
+		db = db.Select(columns)
+	}
+	for _, key := range q.keys() {
+		if key.Desc {
+			db = db.Order(sortColumns[key.Field] + " DESC")
+		} else {
+			db = db.Order(sortColumns[key.Field])
+		}
+	}
+	return db
+}
+
+func (f EmployeeFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Position != "" {
+		db = db.Where("position = ?", f.Position)
+	}
+	if f.PositionPrefix != "" {
+		db = db.Where("LOWER(position) LIKE ? ESCAPE '!'", escapeLike(strings.ToLower(f.PositionPrefix))+"%")
+	}
+	if f.NameContains != "" {
+		db = db.Where("LOWER(name) LIKE ? ESCAPE '!'", "%"+escapeLike(strings.ToLower(f.NameContains))+"%")
+	}
+	if f.SalaryGTE != nil {
+		db = db.Where("salary >= ?", *f.SalaryGTE)
+	}
+	if f.SalaryLTE != nil {
+		db = db.Where("salary <= ?", *f.SalaryLTE)
+	}
+	return db
+}
+
+// applyAfter restricts a GORM query to rows after the given employee in
+// sort order: (a > x) OR (a = x AND b > y) OR ..., with < for descending
+// keys.
+func (q ListQuery) applyAfter(db *gorm.DB, after *models.Employee) *gorm.DB {
+	keys := q.keys()
+	var clauses []string
+	var args []interface{}
+	for i, key := range keys {
+		var parts []string
+		for _, previous := range keys[:i] {
+			parts = append(parts, sortColumns[previous.Field]+" = ?")
+			args = append(args, sortValue(*after, previous.Field))
+		}
+		operator := ">"
+		if key.Desc {
+			operator = "<"
+		}
+		parts = append(parts, sortColumns[key.Field]+" "+operator+" ?")
+		args = append(args, sortValue(*after, key.Field))
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return db.Where("("+strings.Join(clauses, " OR ")+")", args...)
+}
+
+// escapeLike escapes LIKE wildcards with "!", which, unlike a backslash,
+// needs no quoting in any of the supported dialects.
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+// matches is the in-memory equivalent of the filters in apply.
+func (f EmployeeFilter) matches(employee models.Employee) bool {
+	if f.Position != "" && employee.Position != f.Position {
+		return false
+	}
+	if f.PositionPrefix != "" && !strings.HasPrefix(strings.ToLower(employee.Position), strings.ToLower(f.PositionPrefix)) {
+		return false
+	}
+	if f.NameContains != "" && !strings.Contains(strings.ToLower(employee.Name), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	if f.SalaryGTE != nil && employee.Salary < *f.SalaryGTE {
+		return false
+	}
+	if f.SalaryLTE != nil && employee.Salary > *f.SalaryLTE {
+		return false
+	}
+	return true
+}
+
+// compare orders two employees the way apply does: negative when a comes
+// first.
+func (q ListQuery) compare(a, b models.Employee) int {
+	for _, key := range q.keys() {
+		result := compareField(a, b, key.Field)
+		if key.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func compareField(a, b models.Employee, field string) int {
+	switch field {
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "position":
+		return strings.Compare(a.Position, b.Position)
+	case "salary":
+		switch {
+		case a.Salary < b.Salary:
+			return -1
+		case a.Salary > b.Salary:
+			return 1
+		}
+		return 0
+	default:
+		return a.ID - b.ID
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	loggerNew "golang-assessment/logger"
+	"golang-assessment/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupListStores returns both backends seeded with the same employees, so
+// every list test checks that they agree.
+func setupListStores(t *testing.T) map[string]EmployeeStore {
+	stores := map[string]EmployeeStore{
+		"gorm":   NewEmployeeRepository(setupTestDB(t), loggerNew.Discard()),
+		"memory": NewMemoryEmployeeStore(loggerNew.Discard()),
+	}
+	seed := []models.Employee{
+		{Name: "John Doe", Position: "Developer", Salary: 60000},
+		{Name: "Jane Roe", Position: "Manager", Salary: 90000},
+		{Name: "Jim Beam", Position: "Developer", Salary: 75000},
+		{Name: "Ann 50% Off", Position: "Dev Rel", Salary: 60000},
+		{Name: "Bob Stone", Position: "Tester", Salary: 45000},
+	}
+	for _, store := range stores {
+		for _, employee := range seed {
+			store.CreateEmployee(context.Background(), &employee)
+		}
+	}
+	return stores
+}
+
+func ids(employees []models.Employee) []int {
+	result := make([]int, len(employees))
+	for i, employee := range employees {
+		result[i] = employee.ID
+	}
+	return result
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestListQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		query ListQuery
+		want  []int
+	}{
+		{"default order", ListQuery{}, []int{1, 2, 3, 4, 5}},
+		{"position exact", ListQuery{Filter: EmployeeFilter{Position: "Developer"}}, []int{1, 3}},
+		{"position prefix", ListQuery{Filter: EmployeeFilter{PositionPrefix: "dev"}}, []int{1, 3, 4}},
+		{"name contains", ListQuery{Filter: EmployeeFilter{NameContains: "DOE"}}, []int{1}},
+		{"name contains wildcard", ListQuery{Filter: EmployeeFilter{NameContains: "0%"}}, []int{4}},
+		{"salary range", ListQuery{Filter: EmployeeFilter{SalaryGTE: float(60000), SalaryLTE: float(75000)}}, []int{1, 3, 4}},
+		{"sort descending with tie-break", ListQuery{Sort: []SortKey{{Field: "salary", Desc: true}}}, []int{2, 3, 1, 4, 5}},
+		{"multi-field sort", ListQuery{Sort: []SortKey{{Field: "salary", Desc: true}, {Field: "name"}}}, []int{2, 3, 4, 1, 5}},
+		{"id descending", ListQuery{Sort: []SortKey{{Field: "id", Desc: true}}}, []int{5, 4, 3, 2, 1}},
+	}
+
+	for name, store := range setupListStores(t) {
+		for _, tc := range cases {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				employees, total, err := store.ListEmployee(context.Background(), tc.query, 0, 10)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.want, ids(employees))
+				assert.Equal(t, int64(len(tc.want)), total)
+			})
+		}
+	}
+}
+
+func TestListEmployeesAfter_Keyset(t *testing.T) {
+	query := ListQuery{
+		Filter: EmployeeFilter{SalaryGTE: float(50000)},
+		Sort:   []SortKey{{Field: "salary", Desc: true}, {Field: "name"}},
+	}
+
+	for name, store := range setupListStores(t) {
+		t.Run(name, func(t *testing.T) {
+			// Walk two at a time, resuming from the key of the last row
+			var walked []int
+			var after *models.Employee
+			for {
+				page, err := store.ListEmployeesAfter(context.Background(), query, after, 2)
+				assert.Nil(t, err)
+				if len(page) == 0 {
+					break
+				}
+				walked = append(walked, ids(page)...)
+				after, err = query.After(query.KeyOf(page[len(page)-1]))
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, []int{2, 3, 4, 1}, walked)
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	for name, store := range setupListStores(t) {
+		t.Run(name+"/get", func(t *testing.T) {
+			employee, err := store.GetEmployeeByID(context.Background(), 1, Fields{"name"})
+			assert.Nil(t, err)
+			assert.Equal(t, models.Employee{ID: 1, Name: "John Doe", Version: 1}, employee)
+		})
+
+		t.Run(name+"/list keeps the sort keys", func(t *testing.T) {
+			query := ListQuery{Sort: []SortKey{{Field: "salary", Desc: true}}, Fields: Fields{"position"}}
+			employees, _, err := store.ListEmployee(context.Background(), query, 0, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, []models.Employee{{ID: 2, Position: "Manager", Salary: 90000, Version: 1}}, employees)
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("name, position")
+	assert.Nil(t, err)
+	assert.Equal(t, Fields{"name", "position"}, fields)
+
+	_, err = ParseFields("name,ssn")
+	assert.EqualError(t, err, `unknown field "ssn", selectable fields are created_at, created_by, deleted_at, deleted_by, id, name, position, salary, updated_at, updated_by`)
+
+	_, err = ParseFields("id,id")
+	assert.EqualError(t, err, `"id" appears more than once`)
+}
+
+func TestParseSort(t *testing.T) {
+	keys, err := ParseSort("-salary, name")
+	assert.Nil(t, err)
+	assert.Equal(t, []SortKey{{Field: "salary", Desc: true}, {Field: "name"}}, keys)
+
+	_, err = ParseSort("age")
+	assert.EqualError(t, err, `cannot sort by "age", sortable fields are id, name, position, salary`)
+
+	_, err = ParseSort("name,-name")
+	assert.EqualError(t, err, `"name" appears more than once`)
+}
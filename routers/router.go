@@ -1,26 +1,71 @@
 package routers
 
 import (
+	"fmt"
+	"golang-assessment/auth"
+	"golang-assessment/config"
 	"golang-assessment/controller"
+	"golang-assessment/logger"
+	"golang-assessment/metrics"
+	"golang-assessment/pagination"
+	"golang-assessment/problem"
+	"golang-assessment/redact"
 	repository "golang-assessment/respository"
 	"golang-assessment/services"
+	"golang-assessment/tracing"
+	"golang-assessment/validation"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"github.com/sirupsen/logrus"
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
-	employeeRepo := repository.NewEmployeeRepository(db)
-	employeeService := services.NewEmployeeService(employeeRepo)
-	employeeController := controller.NewEmployeeController(employeeService)
+func SetupRouter(cfg *config.AppConfig, store repository.EmployeeStore, cursors *pagination.CursorCodec, healthService *services.HealthService, appMetrics *metrics.Metrics, log *logrus.Logger) *gin.Engine {
+	policy, limits := redact.NewPolicy(cfg.Redaction), pagination.NewLimits(cfg.Pagination)
+	employeeService := services.NewEmployeeService(store, validation.New(cfg.Validation))
+	employeeController := controller.NewEmployeeController(employeeService, policy, limits, cfg.Concurrency, cfg.SoftDelete, cursors, log)
+	auditController := controller.NewAuditController(services.NewAuditService(store), policy, limits, cfg.Audit, cursors, log)
+	healthController := controller.NewHealthController(healthService, log)
 
-	router := gin.Default()
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.Use(
+		gin.CustomRecoveryWithWriter(log.WriterLevel(logrus.ErrorLevel), func(c *gin.Context, recovered any) {
+			problem.Write(c, fmt.Errorf("panic: %v", recovered))
+		}),
+		logger.RequestID(),
+		appMetrics.Middleware(),
+		tracing.Middleware(),
+		// After tracing so access log lines carry the trace ID
+		logger.AccessLog(log),
+		// Innermost, so the logs and metrics above see the status it writes
+		problem.Middleware(),
+	)
+	router.NoRoute(func(c *gin.Context) {
+		problem.Fail(c, problem.New(http.StatusNotFound, problem.CodeRouteNotFound, "no route for "+c.Request.URL.Path))
+	})
+	router.NoMethod(func(c *gin.Context) {
+		problem.Fail(c, problem.New(http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, c.Request.Method+" is not allowed on "+c.Request.URL.Path))
+	})
 
-	router.POST("/employees", employeeController.CreateEmployee)
-	router.GET("/employees/:id", employeeController.GetEmployeeByID)
-	router.PUT("/employees/:id", employeeController.UpdateEmployee)
-	router.DELETE("/employees/:id", employeeController.DeleteEmployee)
-	router.GET("/employees", employeeController.ListEmployees)
+	router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	router.GET("/health", healthController.Health)
+
+	employees := router.Group("/employees", auth.Middleware(cfg.Auth))
+	employees.POST("", employeeController.CreateEmployee)
+	employees.GET("/:id", employeeController.GetEmployeeByID)
+	employees.PUT("/:id", employeeController.UpdateEmployee)
+	employees.PATCH("/:id", employeeController.PatchEmployee)
+	employees.DELETE("/:id", employeeController.DeleteEmployee)
+	employees.POST("/:id/restore", employeeController.RestoreEmployee)
+	employees.GET("", employeeController.ListEmployees)
+	employees.GET("/:id/audit", auditController.ListEmployeeAudit)
+
+	audit := router.Group("/audit", auth.Middleware(cfg.Auth))
+	audit.GET("", auditController.ListAudit)
 
 	return router
 }
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-assessment/config"
+	stdlog "log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ShutdownFunc releases a resource once the server has stopped taking
+// requests. ctx carries the remainder of the shutdown deadline.
+type ShutdownFunc func(ctx context.Context) error
+
+type shutdownHook struct {
+	name string
+	fn   ShutdownFunc
+}
+
+// Server wraps http.Server with timeouts from config and an orderly
+// shutdown: stop accepting connections, drain in-flight requests, then run
+// the registered hooks (database pool, background workers, ...).
+type Server struct {
+	log             *logrus.Logger
+	httpServer      *http.Server
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+
+	mu         sync.Mutex
+	hooks      []shutdownHook
+	drainHooks []func()
+	drainOnce  sync.Once
+}
+
+func New(cfg config.ServerConfig, handler http.Handler, log *logrus.Logger) *Server {
+	return &Server{
+		log: log,
+		httpServer: &http.Server{
+			Addr:              cfg.Addr(),
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			ErrorLog:          stdlog.New(log.WriterLevel(logrus.ErrorLevel), "", 0),
+		},
+		drainDelay:      cfg.DrainDelay,
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// OnDrain registers fn to run as soon as shutdown starts, while the server is
+// still accepting requests, e.g. to fail the readiness probe.
+func (s *Server) OnDrain(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drainHooks = append(s.drainHooks, fn)
+}
+
+// OnShutdown registers fn to run after the HTTP server has drained. Hooks run
+// in reverse registration order, like deferred calls, so something registered
+// later may still rely on something registered earlier.
+func (s *Server) OnShutdown(name string, fn ShutdownFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run listens on the configured address and serves until ctx is cancelled
+// (e.g. by SIGTERM), then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		s.log.Infof("Starting the server on %s", listener.Addr())
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// The listener failed on its own; still release everything else.
+		shutdownErr := s.runHooks(context.Background())
+		return errors.Join(err, shutdownErr)
+	case <-ctx.Done():
+	}
+
+	s.log.Infof("Shutting down, draining for %s", s.drainDelay)
+	s.drain(context.Background())
+
+	s.log.Infof("Waiting up to %s for in-flight requests", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	return s.Shutdown(shutdownCtx)
+}
+
+// drain runs the drain hooks once and keeps serving for the drain delay.
+func (s *Server) drain(ctx context.Context) {
+	s.drainOnce.Do(func() {
+		s.mu.Lock()
+		hooks := s.drainHooks
+		s.mu.Unlock()
+		for _, fn := range hooks {
+			fn()
+		}
+
+		timer := time.NewTimer(s.drainDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// Shutdown drains (if Run or Serve have not already), stops accepting
+// connections, waits for in-flight requests until ctx expires and then runs
+// the shutdown hooks with whatever time is left.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.drain(ctx)
+
+	var errs []error
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("draining connections: %w", err))
+		// Whatever did not finish in time is cut off
+		s.httpServer.Close()
+	}
+	errs = append(errs, s.runHooks(ctx))
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	s.log.Info("Server stopped")
+	return nil
+}
+
+func (s *Server) runHooks(ctx context.Context) error {
+	s.mu.Lock()
+	hooks := s.hooks
+	s.hooks = nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			s.log.Errorf("Error shutting down %s: %v", hooks[i].name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-assessment/config"
+	loggerNew "golang-assessment/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestServer(t *testing.T, handler http.Handler, shutdownTimeout time.Duration) (*Server, net.Listener) {
+	cfg := config.DefaultConfig().Server
+	cfg.ShutdownTimeout = shutdownTimeout
+	cfg.DrainDelay = 0
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	return New(cfg, handler, loggerNew.Discard()), listener
+}
+
+func TestServer_DrainsInFlightRequests(t *testing.T) {
+	// Setup
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+	srv, listener := setupTestServer(t, handler, 5*time.Second)
+
+	var order []string
+	srv.OnShutdown("database", func(ctx context.Context) error {
+		order = append(order, "database")
+		return nil
+	})
+	srv.OnShutdown("worker", func(ctx context.Context) error {
+		order = append(order, "worker")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, listener) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	// Simulate SIGTERM while the request is still being handled
+	<-started
+	cancel()
+
+	got := <-response
+	assert.Nil(t, got.err)
+	assert.Equal(t, "done", got.body)
+	assert.Nil(t, <-runErr)
+	// Hooks run in reverse registration order
+	assert.Equal(t, []string{"worker", "database"}, order)
+
+	// The listener is closed after shutdown
+	_, err := http.Get("http://" + listener.Addr().String())
+	assert.NotNil(t, err)
+}
+
+func TestServer_ShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	srv, listener := setupTestServer(t, handler, 50*time.Millisecond)
+
+	hookRan := false
+	hookErr := errors.New("close failed")
+	srv.OnShutdown("database", func(ctx context.Context) error {
+		hookRan = true
+		return hookErr
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, listener) }()
+	go http.Get("http://" + listener.Addr().String())
+
+	<-started
+	cancel()
+
+	err := <-runErr
+	// The stuck request is cut off, but the hooks still run
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, errors.Is(err, hookErr))
+	assert.True(t, hookRan)
+}
+
+func TestServer_DrainsBeforeClosing(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	srv, listener := setupTestServer(t, handler, time.Second)
+	srv.drainDelay = 300 * time.Millisecond
+
+	drained := make(chan struct{})
+	srv.OnDrain(func() { close(drained) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, listener) }()
+	cancel()
+
+	// New requests are still served during the drain delay
+	<-drained
+	resp, err := http.Get("http://" + listener.Addr().String())
+	assert.Nil(t, err)
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Nil(t, <-runErr)
+}
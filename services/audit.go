@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"golang-assessment/logger"
+	"golang-assessment/models"
+	repository "golang-assessment/respository"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// auditedFields are the employee fields whose values the audit log records.
+// Who made a change and when are part of every entry instead.
+var auditedFields = []struct {
+	name  string
+	value func(models.Employee) interface{}
+}{
+	{"name", func(e models.Employee) interface{} { return e.Name }},
+	{"position", func(e models.Employee) interface{} { return e.Position }},
+	{"salary", func(e models.Employee) interface{} { return e.Salary }},
+}
+
+// audit appends the entry for a change from before to after, either of which
+// is nil when the employee did not exist on that side. store must be the one
+// the change was made with, so both commit together.
+func audit(ctx context.Context, store repository.EmployeeStore, operation string, id int, before, after *models.Employee) error {
+	return store.AppendAudit(ctx, &models.AuditEntry{
+		EmployeeID: id,
+		Operation:  operation,
+		Actor:      actor(ctx),
+		RequestID:  logger.RequestIDFromContext(ctx),
+		Changes:    diff(before, after),
+	})
+}
+
+// diff lists the audited fields that differ between before and after.
+func diff(before, after *models.Employee) models.AuditChanges {
+	changes := models.AuditChanges{}
+	for _, field := range auditedFields {
+		var change models.AuditChange
+		change.Field = field.name
+		if before != nil {
+			change.Before = field.value(*before)
+		}
+		if after != nil {
+			change.After = field.value(*after)
+		}
+		if change.Before != change.After {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// AuditService reads the audit log. Entries are written by EmployeeService.
+type AuditService struct {
+	store repository.AuditStore
+}
+
+func NewAuditService(store repository.AuditStore) *AuditService {
+	return &AuditService{store: store}
+}
+
+// AuditPage is one page of audit entries. When HasNext is set the next page
+// starts after the last entry.
+type AuditPage struct {
+	Entries []models.AuditEntry
+	Limit   int
+	HasNext bool
+}
+
+// ListAudit returns the entries matching query that come after the entry
+// with ID after, oldest first; after is 0 for the first page.
+func (s *AuditService) ListAudit(ctx context.Context, query repository.AuditQuery, after int64, limit int) (AuditPage, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.ListAudit", trace.WithAttributes(
+		attribute.String("query", query.String()),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	// One extra entry tells whether another page follows
+	entries, err := s.store.ListAudit(ctx, query, after, limit+1)
+	if err != nil {
+		recordError(span, err)
+		return AuditPage{}, err
+	}
+	page := AuditPage{Entries: entries, Limit: limit}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.HasNext = true
+	}
+	return page, nil
+}
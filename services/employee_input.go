@@ -0,0 +1,13 @@
+package services
+
+// EmployeeInput is what a caller may set on an employee, both over HTTP and
+// from jobs such as imports. Its validate tags are the rules every create
+// and update must pass; see the validation package for the custom ones.
+type EmployeeInput struct {
+	// ID is assigned by the store. It is only here so that a client sending
+	// one is told so instead of having it silently ignored.
+	ID       *int    `json:"id,omitempty" validate:"isdefault"`
+	Name     string  `json:"name" validate:"notblank,max=100"`
+	Position string  `json:"position" validate:"notblank,max=100,position"`
+	Salary   float64 `json:"salary" validate:"gt=0,salary"`
+}
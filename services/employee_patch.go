@@ -0,0 +1,88 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-assessment/apperrors"
+	"golang-assessment/models"
+	"golang-assessment/patch"
+	repository "golang-assessment/respository"
+	"reflect"
+)
+
+// ErrInvalidPatchResult means a patch applied cleanly but did not leave a
+// valid employee, e.g. it removed a field or changed the ID.
+var ErrInvalidPatchResult = apperrors.New(apperrors.ErrValidation, "patched employee is invalid")
+
+// patchDocument is the JSON form of an employee that patches apply to:
+// the fields a client may change, plus the id it may not. Fields the store
+// sets, such as timestamps, are not part of it.
+type patchDocument struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Position string  `json:"position"`
+	Salary   float64 `json:"salary"`
+}
+
+// employeeChanges applies p to the patch document of employee and returns
+// the fields whose values it changed.
+func employeeChanges(employee models.Employee, p patch.Patch) (repository.EmployeeChanges, error) {
+	var changes repository.EmployeeChanges
+	doc, err := json.Marshal(patchDocument{ID: employee.ID, Name: employee.Name, Position: employee.Position, Salary: employee.Salary})
+	if err != nil {
+		return changes, err
+	}
+	patched, err := p.Apply(doc)
+	if err != nil {
+		return changes, apperrors.Wrap(apperrors.ErrValidation, err)
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patched, &members); err != nil {
+		return changes, fmt.Errorf("%w: an employee must be a JSON object", ErrInvalidPatchResult)
+	}
+	for _, name := range []string{"id", "name", "position", "salary"} {
+		if value, ok := members[name]; !ok || string(value) == "null" {
+			return changes, fmt.Errorf("%w: %s cannot be removed", ErrInvalidPatchResult, name)
+		}
+		delete(members, name)
+	}
+	for name := range members {
+		return changes, fmt.Errorf("%w: unknown field %q", ErrInvalidPatchResult, name)
+	}
+
+	var result patchDocument
+	if err := json.Unmarshal(patched, &result); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return changes, fmt.Errorf("%w: %s must be a %s", ErrInvalidPatchResult, typeErr.Field, jsonType(typeErr.Type))
+		}
+		return changes, fmt.Errorf("%w: %v", ErrInvalidPatchResult, err)
+	}
+	if result.ID != employee.ID {
+		return changes, fmt.Errorf("%w: id cannot be changed", ErrInvalidPatchResult)
+	}
+	if result.Name != employee.Name {
+		changes.Name = &result.Name
+	}
+	if result.Position != employee.Position {
+		changes.Position = &result.Position
+	}
+	if result.Salary != employee.Salary {
+		changes.Salary = &result.Salary
+	}
+	return changes, nil
+}
+
+// jsonType names the JSON type that decodes into t.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "number"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"golang-assessment/config"
+	repository "golang-assessment/respository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Purger removes soft-deleted employees for good once they have been deleted
+// for longer than the retention period. Until then they can be restored.
+type Purger struct {
+	store     repository.EmployeeStore
+	retention time.Duration
+	interval  time.Duration
+	log       *logrus.Logger
+}
+
+func NewPurger(store repository.EmployeeStore, cfg config.SoftDeleteConfig, log *logrus.Logger) *Purger {
+	return &Purger{store: store, retention: cfg.Retention, interval: cfg.PurgeInterval, log: log}
+}
+
+// Purge removes the employees deleted more than the retention period ago and
+// returns how many there were.
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "Purger.Purge")
+	defer span.End()
+
+	purged, err := p.store.PurgeEmployees(ctx, config.Now().Add(-p.retention))
+	recordError(span, err)
+	return purged, err
+}
+
+// Run purges straight away and then every interval until ctx is done. A
+// failed purge is logged and retried at the next interval. Every replica may
+// run it; purging the same rows twice is harmless.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+			p.log.WithContext(ctx).Errorf("Error purging deleted employees: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,67 +1,85 @@
 package services_test
 
 import (
+	"context"
+	"errors"
+	"golang-assessment/apperrors"
+	"golang-assessment/auth"
+	"golang-assessment/config"
 	"golang-assessment/models"
 	repository "golang-assessment/respository"
 
 	loggerNew "golang-assessment/logger"
+	"golang-assessment/patch"
 	"golang-assessment/services"
+	"golang-assessment/validation"
 	"testing"
+	"time"
 
-	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
-func setupTestLogger() {
-	// Initialize a buffer to capture log output
-	logger := logrus.New()
-	// Replace the global logger with the mock logger
-	loggerNew.Log = logger
+func setupTestStore(t *testing.T) repository.EmployeeStore {
+	// Seed an in-memory store so the tests do not need a database
+	store := repository.NewMemoryEmployeeStore(loggerNew.Discard())
+	seed := []models.Employee{
+		{Name: "John Doe", Position: "Developer", Salary: 60000},
+		{Name: "Jane Doe", Position: "Manager", Salary: 60000},
+	}
+	for i := range seed {
+		store.CreateEmployee(context.Background(), &seed[i])
+	}
+	return store
 }
-func setupTestDB(t *testing.T) *gorm.DB {
-	// Connect to your test database
-	dsn := "host=localhost user=postgres password=root123 dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Shanghai"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("error opening database: %v", err)
+
+// withoutTimestamps zeroes the times the store sets, for comparing employees.
+func withoutTimestamps(employees ...models.Employee) []models.Employee {
+	for i := range employees {
+		employees[i].CreatedAt = time.Time{}
+		employees[i].UpdatedAt = time.Time{}
 	}
-	return db
+	return employees
 }
 
 func TestEmployeeService_CreateEmployee(t *testing.T) {
 	// Setup
-	setupTestLogger()
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
 
 	// Test case: Valid employee creation
 	t.Run("TestCreateEmployee_ValidData", func(t *testing.T) {
 		expectedEmployee := models.Employee{Name: "John Doe", Position: "Software Engineer", Salary: 50000}
 
-		createdEmployee := service.CreateEmployee(expectedEmployee.Name, expectedEmployee.Position, expectedEmployee.Salary)
+		createdEmployee, err := service.CreateEmployee(context.Background(), services.EmployeeInput{Name: expectedEmployee.Name, Position: expectedEmployee.Position, Salary: expectedEmployee.Salary})
 
 		// Assert the created employee
+		assert.Nil(t, err)
+		assert.Equal(t, 3, createdEmployee.ID)
 		assert.Equal(t, expectedEmployee.Name, createdEmployee.Name)
 		assert.Equal(t, expectedEmployee.Position, createdEmployee.Position)
 		assert.Equal(t, expectedEmployee.Salary, createdEmployee.Salary)
 	})
+
+	// Test case: Invalid input is not stored
+	t.Run("TestCreateEmployee_Invalid", func(t *testing.T) {
+		_, err := service.CreateEmployee(context.Background(), services.EmployeeInput{Name: "John Doe", Position: "Developer"})
+
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
+		count, _ := repo.CountEmployees(context.Background())
+		assert.Equal(t, int64(3), count)
+	})
 }
 
 func TestEmployeeService_GetEmployeeByID(t *testing.T) {
 	// Setup
-	setupTestLogger()
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
 
 	// Test case: Valid employee ID
 	t.Run("TestGetEmployeeByID_ValidID", func(t *testing.T) {
-		expectedEmployee := models.Employee{ID: 15, Name: "John Doe", Position: "Developer", Salary: 60000}
+		expectedEmployee := models.Employee{ID: 1, Name: "John Doe", Position: "Developer", Salary: 60000}
 
-		employee, err := service.GetEmployeeByID(15)
+		employee, err := service.GetEmployeeByID(context.Background(), 1, nil)
 
 		// Assert the retrieved employee
 		assert.Nil(t, err)
@@ -73,23 +91,21 @@ func TestEmployeeService_GetEmployeeByID(t *testing.T) {
 
 	// Test case: Invalid employee ID
 	t.Run("TestGetEmployeeByID_InvalidID", func(t *testing.T) {
-		_, err := service.GetEmployeeByID(100)
+		_, err := service.GetEmployeeByID(context.Background(), 100, nil)
 
 		// Assert that an error occurred due to invalid ID
-		assert.NotNil(t, err)
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
 	})
 }
 
 func TestEmployeeService_UpdateEmployee(t *testing.T) {
-	setupTestLogger()
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
 	// Test case: Valid employee update
 	t.Run("TestUpdateEmployee_ValidData", func(t *testing.T) {
-		expectedEmployee := models.Employee{ID: 16, Name: "Updated Name", Position: "Updated Position", Salary: 60000}
+		expectedEmployee := models.Employee{ID: 2, Name: "Updated Name", Position: "Updated Position", Salary: 60000}
 
-		updatedEmployee, err := service.UpdateEmployee(16, expectedEmployee.Name, expectedEmployee.Position, expectedEmployee.Salary)
+		updatedEmployee, err := service.UpdateEmployee(context.Background(), 2, nil, services.EmployeeInput{Name: expectedEmployee.Name, Position: expectedEmployee.Position, Salary: expectedEmployee.Salary})
 
 		// Assert the updated employee
 		assert.Nil(t, err)
@@ -97,11 +113,32 @@ func TestEmployeeService_UpdateEmployee(t *testing.T) {
 		assert.Equal(t, expectedEmployee.Name, updatedEmployee.Name)
 		assert.Equal(t, expectedEmployee.Position, updatedEmployee.Position)
 		assert.Equal(t, expectedEmployee.Salary, updatedEmployee.Salary)
+		assert.Equal(t, 2, updatedEmployee.Version)
+		assert.True(t, updatedEmployee.UpdatedAt.After(updatedEmployee.CreatedAt))
+	})
+
+	t.Run("TestUpdateEmployee_IfMatch", func(t *testing.T) {
+		_, err := service.UpdateEmployee(context.Background(), 1, services.IfMatch{5}, services.EmployeeInput{Name: "Jane Doe", Position: "Manager", Salary: 60000})
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+		// A list that matches nothing, e.g. only weak tags, allows no version
+		_, err = service.UpdateEmployee(context.Background(), 1, services.IfMatch{}, services.EmployeeInput{Name: "Jane Doe", Position: "Manager", Salary: 60000})
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+		updatedEmployee, err := service.UpdateEmployee(context.Background(), 1, services.IfMatch{3, 1}, services.EmployeeInput{Name: "Jane Doe", Position: "Manager", Salary: 60000})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, updatedEmployee.Version)
+	})
+
+	t.Run("TestUpdateEmployee_Invalid", func(t *testing.T) {
+		_, err := service.UpdateEmployee(context.Background(), 2, nil, services.EmployeeInput{Name: "Jane Doe", Position: "Manager", Salary: 5000000})
+
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
 	})
 
 	// Test case: Error updating employee
 	t.Run("TestUpdateEmployee_Error", func(t *testing.T) {
-		updatedEmployee, err := service.UpdateEmployee(90000, "Jane Doe", "Manager", 60000)
+		updatedEmployee, err := service.UpdateEmployee(context.Background(), 90000, nil, services.EmployeeInput{Name: "Jane Doe", Position: "Manager", Salary: 60000})
 
 		// Assert that an error occurred during update
 		assert.NotNil(t, err)
@@ -110,14 +147,12 @@ func TestEmployeeService_UpdateEmployee(t *testing.T) {
 }
 
 func TestEmployeeService_DeleteEmployee(t *testing.T) {
-	setupTestLogger()
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
 
 	// Test case: Valid employee deletion
 	t.Run("TestDeleteEmployee_ValidID", func(t *testing.T) {
-		err := service.DeleteEmployee(8)
+		err := service.DeleteEmployee(context.Background(), 1, nil)
 
 		// Assert no error occurred during deletion
 		assert.Nil(t, err)
@@ -126,32 +161,305 @@ func TestEmployeeService_DeleteEmployee(t *testing.T) {
 	// Test case: Error deleting employee
 	t.Run("TestDeleteEmployee_Error", func(t *testing.T) {
 
-		err := service.DeleteEmployee(1)
+		err := service.DeleteEmployee(context.Background(), 1, nil)
 
 		// Assert that an error occurred during deletion
 		assert.NotNil(t, err)
 	})
 }
 
+func TestEmployeeService_RestoreEmployee(t *testing.T) {
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
+	ctx := auth.WithPrincipal(context.Background(), config.Principal{Subject: "hannah", Role: "hr"})
+
+	t.Run("TestRestoreEmployee_NotDeleted", func(t *testing.T) {
+		_, err := service.RestoreEmployee(ctx, 1, nil)
+
+		assert.ErrorIs(t, err, services.ErrNotDeleted)
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+	})
+
+	t.Run("TestRestoreEmployee_RecordsActors", func(t *testing.T) {
+		assert.Nil(t, service.DeleteEmployee(ctx, 1, nil))
+		deleted, err := service.GetEmployeeByID(repository.IncludeDeleted(ctx), 1, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "hannah", deleted.DeletedBy)
+
+		_, err = service.RestoreEmployee(ctx, 1, services.IfMatch{1})
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+
+		restored, err := service.RestoreEmployee(auth.WithPrincipal(context.Background(), config.Principal{Subject: "ada", Role: "admin"}), 1, services.IfMatch{2})
+		assert.Nil(t, err)
+		assert.False(t, restored.IsDeleted())
+		assert.Equal(t, "ada", restored.UpdatedBy)
+		assert.Equal(t, 3, restored.Version)
+	})
+
+	t.Run("TestRestoreEmployee_Purged", func(t *testing.T) {
+		assert.Nil(t, service.DeleteEmployee(ctx, 2, nil))
+		repo.PurgeEmployees(ctx, time.Now().Add(time.Second))
+
+		_, err := service.RestoreEmployee(ctx, 2, nil)
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func TestPurger(t *testing.T) {
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
+	assert.Nil(t, service.DeleteEmployee(context.Background(), 1, nil))
+
+	t.Run("TestPurge_KeepsRecentDeletes", func(t *testing.T) {
+		purger := services.NewPurger(repo, config.SoftDeleteConfig{Retention: time.Hour, PurgeInterval: time.Hour}, loggerNew.Discard())
+
+		purged, err := purger.Purge(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), purged)
+	})
+
+	t.Run("TestRun_PurgesUntilCancelled", func(t *testing.T) {
+		// A negative retention purges employees deleted up to a second from now
+		purger := services.NewPurger(repo, config.SoftDeleteConfig{Retention: -time.Second, PurgeInterval: time.Millisecond}, loggerNew.Discard())
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			purger.Run(ctx)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool {
+			_, err := service.GetEmployeeByID(repository.IncludeDeleted(context.Background()), 1, nil)
+			return errors.Is(err, apperrors.ErrNotFound)
+		}, time.Second, time.Millisecond)
+		cancel()
+		<-done
+		// Employees that are not deleted stay
+		count, _ := repo.CountEmployees(context.Background())
+		assert.Equal(t, int64(1), count)
+	})
+}
+
 func TestEmployeeService_ListEmployees(t *testing.T) {
-	setupTestLogger()
-	db := setupTestDB(t)
-	repo := repository.NewEmployeeRepository(db)
-	service := services.NewEmployeeService(repo)
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
 
 	// Test case: Valid list of employees
 	t.Run("TestListEmployees_ValidData", func(t *testing.T) {
 		expectedEmployees := []models.Employee{
-			{ID: 1, Name: "John Doe", Position: "Software Engineer", Salary: 50000},
-			{ID: 2, Name: "Jane Doe", Position: "Manager", Salary: 60000},
-			// Add more expected employees if needed
+			{ID: 1, Name: "John Doe", Position: "Developer", Salary: 60000, Version: 1},
+			{ID: 2, Name: "Jane Doe", Position: "Manager", Salary: 60000, Version: 1},
 		}
 
-		employees, err := service.ListEmployees(1, 10)
+		page, err := service.ListEmployees(context.Background(), repository.ListQuery{}, 1, 10)
 
 		// Assert the list of employees
 		assert.Nil(t, err)
-		assert.NotNil(t, expectedEmployees, employees)
+		assert.Equal(t, expectedEmployees, withoutTimestamps(page.Employees...))
+		assert.Equal(t, int64(2), page.Total)
+		assert.Equal(t, 1, page.TotalPages())
+		assert.False(t, page.HasNext())
+	})
+
+	// Test case: More pages to come
+	t.Run("TestListEmployees_HasNext", func(t *testing.T) {
+		page, err := service.ListEmployees(context.Background(), repository.ListQuery{}, 1, 1)
+
+		assert.Nil(t, err)
+		assert.Len(t, page.Employees, 1)
+		assert.Equal(t, 2, page.TotalPages())
+		assert.True(t, page.HasNext())
+	})
+
+	// Test case: Page past the end
+	t.Run("TestListEmployees_EmptyPage", func(t *testing.T) {
+		page, err := service.ListEmployees(context.Background(), repository.ListQuery{}, 2, 10)
+
+		assert.Nil(t, err)
+		assert.Empty(t, page.Employees)
+		assert.Equal(t, int64(2), page.Total)
+	})
+}
+
+func TestEmployeeService_ListEmployeesAfter(t *testing.T) {
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
+
+	// Test case: More employees after this page
+	t.Run("TestListEmployeesAfter_HasNext", func(t *testing.T) {
+		page, err := service.ListEmployeesAfter(context.Background(), repository.ListQuery{}, nil, 1)
+
+		assert.Nil(t, err)
+		assert.Len(t, page.Employees, 1)
+		assert.True(t, page.HasNext)
+		assert.Equal(t, 1, page.Employees[0].ID)
+	})
+
+	// Test case: Last page
+	t.Run("TestListEmployeesAfter_LastPage", func(t *testing.T) {
+		page, err := service.ListEmployeesAfter(context.Background(), repository.ListQuery{}, &models.Employee{ID: 1}, 1)
 
+		assert.Nil(t, err)
+		assert.Len(t, page.Employees, 1)
+		assert.Equal(t, 2, page.Employees[0].ID)
+		assert.False(t, page.HasNext)
+	})
+}
+
+func TestEmployeeService_PatchEmployee(t *testing.T) {
+	parse := func(mediaType, body string) patch.Patch {
+		p, err := patch.Parse(mediaType, []byte(body))
+		if err != nil {
+			t.Fatalf("error parsing patch: %v", err)
+		}
+		return p
+	}
+
+	t.Run("TestPatchEmployee_MergePatchKeepsOtherFields", func(t *testing.T) {
+		service := services.NewEmployeeService(setupTestStore(t), validation.New(config.DefaultConfig().Validation))
+
+		employee, err := service.PatchEmployee(context.Background(), 1, nil, parse(patch.MergePatchType, `{"position":"Lead"}`))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []models.Employee{{ID: 1, Name: "John Doe", Position: "Lead", Salary: 60000, Version: 2, UpdatedBy: "system"}}, withoutTimestamps(employee))
+		assert.True(t, employee.UpdatedAt.After(employee.CreatedAt))
+		stored, _ := service.GetEmployeeByID(context.Background(), 1, nil)
+		assert.Equal(t, employee, stored)
+	})
+
+	t.Run("TestPatchEmployee_JSONPatch", func(t *testing.T) {
+		service := services.NewEmployeeService(setupTestStore(t), validation.New(config.DefaultConfig().Validation))
+
+		employee, err := service.PatchEmployee(context.Background(), 2, nil, parse(patch.JSONPatchType,
+			`[{"op":"test","path":"/salary","value":60000},{"op":"replace","path":"/salary","value":65000}]`))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []models.Employee{{ID: 2, Name: "Jane Doe", Position: "Manager", Salary: 65000, Version: 2, UpdatedBy: "system"}}, withoutTimestamps(employee))
+	})
+
+	t.Run("TestPatchEmployee_InvalidResult", func(t *testing.T) {
+		service := services.NewEmployeeService(setupTestStore(t), validation.New(config.DefaultConfig().Validation))
+		for body, message := range map[string]string{
+			`{"salary":null}`:                       "patched employee is invalid: salary cannot be removed",
+			`{"id":7}`:                              "patched employee is invalid: id cannot be changed",
+			`{"salary":"lots"}`:                     "patched employee is invalid: salary must be a number",
+			`{"age":40}`:                            `patched employee is invalid: unknown field "age"`,
+			`{"created_at":"2024-01-01T00:00:00Z"}`: `patched employee is invalid: unknown field "created_at"`,
+			`["not","an","object"]`:                 "patched employee is invalid: an employee must be a JSON object",
+		} {
+			_, err := service.PatchEmployee(context.Background(), 1, nil, parse(patch.MergePatchType, body))
+			assert.ErrorIs(t, err, services.ErrInvalidPatchResult, body)
+			assert.ErrorIs(t, err, apperrors.ErrValidation, body)
+			assert.EqualError(t, err, message, body)
+		}
+		stored, _ := service.GetEmployeeByID(context.Background(), 1, nil)
+		assert.Equal(t, 60000.0, stored.Salary)
+	})
+
+	t.Run("TestPatchEmployee_BreaksRules", func(t *testing.T) {
+		service := services.NewEmployeeService(setupTestStore(t), validation.New(config.DefaultConfig().Validation))
+
+		_, err := service.PatchEmployee(context.Background(), 1, nil, parse(patch.MergePatchType, `{"name":"","salary":-1}`))
+
+		assert.EqualError(t, err, "validation failed: name is required; salary must be greater than 0")
+		stored, _ := service.GetEmployeeByID(context.Background(), 1, nil)
+		assert.Equal(t, "John Doe", stored.Name)
+	})
+
+	t.Run("TestPatchEmployee_FailedTest", func(t *testing.T) {
+		service := services.NewEmployeeService(setupTestStore(t), validation.New(config.DefaultConfig().Validation))
+
+		_, err := service.PatchEmployee(context.Background(), 1, nil, parse(patch.JSONPatchType, `[{"op":"test","path":"/salary","value":1}]`))
+
+		assert.ErrorIs(t, err, patch.ErrApply)
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
+	})
+
+	t.Run("TestPatchEmployee_NotFound", func(t *testing.T) {
+		service := services.NewEmployeeService(setupTestStore(t), validation.New(config.DefaultConfig().Validation))
+
+		_, err := service.PatchEmployee(context.Background(), 100, nil, parse(patch.MergePatchType, `{}`))
+
+		assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func TestEmployeeService_Audit(t *testing.T) {
+	// Setup
+	repo := setupTestStore(t)
+	service := services.NewEmployeeService(repo, validation.New(config.DefaultConfig().Validation))
+	auditService := services.NewAuditService(repo)
+	ctx := loggerNew.WithRequestID(auth.WithPrincipal(context.Background(), config.Principal{Subject: "hannah", Role: "hr"}), "req-1")
+	entries := func(t *testing.T) []models.AuditEntry {
+		page, err := auditService.ListAudit(context.Background(), repository.AuditQuery{EmployeeID: 3}, 0, 100)
+		assert.Nil(t, err)
+		return page.Entries
+	}
+
+	t.Run("TestAudit_EveryWriteIsRecorded", func(t *testing.T) {
+		employee, err := service.CreateEmployee(ctx, services.EmployeeInput{Name: "Jim Beam", Position: "Developer", Salary: 50000})
+		assert.Nil(t, err)
+		_, err = service.UpdateEmployee(ctx, employee.ID, nil, services.EmployeeInput{Name: "Jim Beam", Position: "Lead", Salary: 55000})
+		assert.Nil(t, err)
+		p, _ := patch.Parse(patch.MergePatchType, []byte(`{"salary":57000}`))
+		_, err = service.PatchEmployee(ctx, employee.ID, nil, p)
+		assert.Nil(t, err)
+		assert.Nil(t, service.DeleteEmployee(ctx, employee.ID, nil))
+		_, err = service.RestoreEmployee(auth.WithPrincipal(ctx, config.Principal{Subject: "ada", Role: "admin"}), employee.ID, nil)
+		assert.Nil(t, err)
+
+		recorded := entries(t)
+		var operations, actors []string
+		for _, entry := range recorded {
+			operations = append(operations, entry.Operation)
+			actors = append(actors, entry.Actor)
+			assert.Equal(t, "req-1", entry.RequestID)
+			assert.False(t, entry.Timestamp.IsZero())
+		}
+		assert.Equal(t, []string{models.AuditCreate, models.AuditUpdate, models.AuditUpdate, models.AuditDelete, models.AuditRestore}, operations)
+		assert.Equal(t, []string{"hannah", "hannah", "hannah", "hannah", "ada"}, actors)
+
+		assert.Equal(t, models.AuditChanges{
+			{Field: "name", Before: nil, After: "Jim Beam"},
+			{Field: "position", Before: nil, After: "Developer"},
+			{Field: "salary", Before: nil, After: float64(50000)},
+		}, recorded[0].Changes)
+		// Only the fields that changed
+		assert.Equal(t, models.AuditChanges{
+			{Field: "position", Before: "Developer", After: "Lead"},
+			{Field: "salary", Before: float64(50000), After: float64(55000)},
+		}, recorded[1].Changes)
+		assert.Equal(t, models.AuditChanges{{Field: "salary", Before: float64(55000), After: float64(57000)}}, recorded[2].Changes)
+		assert.Equal(t, models.AuditChanges{
+			{Field: "name", Before: "Jim Beam", After: nil},
+			{Field: "position", Before: "Lead", After: nil},
+			{Field: "salary", Before: float64(57000), After: nil},
+		}, recorded[3].Changes)
+		assert.Len(t, recorded[4].Changes, 3)
+	})
+
+	t.Run("TestAudit_FailedWritesAreNotRecorded", func(t *testing.T) {
+		_, err := service.UpdateEmployee(ctx, 3, services.IfMatch{1}, services.EmployeeInput{Name: "Jim Beam", Position: "Lead", Salary: 1})
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+		_, err = service.UpdateEmployee(ctx, 3, nil, services.EmployeeInput{Name: "", Position: "Lead", Salary: 1})
+		assert.NotNil(t, err)
+		// A patch that changes nothing writes nothing
+		p, _ := patch.Parse(patch.MergePatchType, []byte(`{}`))
+		_, err = service.PatchEmployee(ctx, 3, nil, p)
+		assert.Nil(t, err)
+
+		assert.Len(t, entries(t), 5)
+	})
+
+	t.Run("TestAudit_Pages", func(t *testing.T) {
+		page, err := auditService.ListAudit(context.Background(), repository.AuditQuery{}, 0, 3)
+		assert.Nil(t, err)
+		assert.Len(t, page.Entries, 3)
+		assert.True(t, page.HasNext)
+
+		page, err = auditService.ListAudit(context.Background(), repository.AuditQuery{}, page.Entries[2].ID, 3)
+		assert.Nil(t, err)
+		assert.Len(t, page.Entries, 2)
+		assert.False(t, page.HasNext)
 	})
 }
@@ -1,45 +1,297 @@
 package services
 
 import (
+	"context"
+	"golang-assessment/apperrors"
+	"golang-assessment/auth"
 	"golang-assessment/models"
+	"golang-assessment/patch"
 	repository "golang-assessment/respository"
+	"golang-assessment/validation"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("golang-assessment/services")
+
+// ErrNotDeleted means a restore was asked for an employee that is not
+// deleted.
+var ErrNotDeleted = apperrors.New(apperrors.ErrConflict, "employee is not deleted")
+
 type EmployeeService struct {
-	repository *repository.EmployeeRepository
+	repository repository.EmployeeStore
+	validator  *validation.Validator
 }
 
-func NewEmployeeService(repository *repository.EmployeeRepository) *EmployeeService {
-	return &EmployeeService{repository: repository}
+func NewEmployeeService(repository repository.EmployeeStore, validator *validation.Validator) *EmployeeService {
+	return &EmployeeService{repository: repository, validator: validator}
 }
 
-func (s *EmployeeService) CreateEmployee(name, position string, salary float64) models.Employee {
-	employee := models.Employee{Name: name, Position: position, Salary: salary}
-	s.repository.CreateEmployee(&employee)
-	return employee
-}
+// IfMatch lists the versions of an employee a write may apply to, from an
+// If-Match header. Nil allows any version; an empty, non-nil IfMatch allows
+// none. Either way the write fails with repository.ErrVersionMismatch if the
+// employee changes between reading and writing it.
+type IfMatch []int
 
-func (s *EmployeeService) GetEmployeeByID(id int) (models.Employee, error) {
-	return s.repository.GetEmployeeByID(id)
+func (m IfMatch) allows(version int) bool {
+	if m == nil {
+		return true
+	}
+	for _, allowed := range m {
+		if allowed == version {
+			return true
+		}
+	}
+	return false
 }
 
-func (s *EmployeeService) UpdateEmployee(id int, name, position string, salary float64) (models.Employee, error) {
-	employee, err := s.repository.GetEmployeeByID(id)
+// CreateEmployee checks input against its rules before storing it; a
+// failing input is a *validation.Error. Like every write here, it records an
+// audit entry in the same transaction.
+func (s *EmployeeService) CreateEmployee(ctx context.Context, input EmployeeInput) (models.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.CreateEmployee")
+	defer span.End()
+
+	if err := s.validator.Struct(input); err != nil {
+		recordError(span, err)
+		return models.Employee{}, err
+	}
+	employee := models.Employee{Name: input.Name, Position: input.Position, Salary: input.Salary, CreatedBy: actor(ctx), UpdatedBy: actor(ctx)}
+	err := s.repository.WithTx(ctx, func(store repository.EmployeeStore) error {
+		if err := store.CreateEmployee(ctx, &employee); err != nil {
+			return err
+		}
+		return audit(ctx, store, models.AuditCreate, employee.ID, nil, &employee)
+	})
 	if err != nil {
+		recordError(span, err)
 		return models.Employee{}, err
 	}
-	employee.Name = name
-	employee.Position = position
-	employee.Salary = salary
-	err = s.repository.UpdateEmployee(&employee)
+	span.SetAttributes(attribute.Int("employee.id", employee.ID))
+	return employee, nil
+}
+
+// GetEmployeeByID reads the employee's fields, or all of them when fields is
+// empty. A deleted employee is only found in a repository.IncludeDeleted
+// context; the same goes for the lists.
+func (s *EmployeeService) GetEmployeeByID(ctx context.Context, id int, fields repository.Fields) (models.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.GetEmployeeByID", trace.WithAttributes(attribute.Int("employee.id", id)))
+	defer span.End()
+
+	employee, err := s.repository.GetEmployeeByID(ctx, id, fields)
+	recordError(span, err)
 	return employee, err
 }
 
-func (s *EmployeeService) DeleteEmployee(id int) error {
-	return s.repository.DeleteEmployee(id)
+// UpdateEmployee reads and rewrites the employee in one transaction. input
+// must pass the same rules as on create.
+func (s *EmployeeService) UpdateEmployee(ctx context.Context, id int, ifMatch IfMatch, input EmployeeInput) (models.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.UpdateEmployee", trace.WithAttributes(attribute.Int("employee.id", id)))
+	defer span.End()
+
+	if err := s.validator.Struct(input); err != nil {
+		recordError(span, err)
+		return models.Employee{}, err
+	}
+	var employee models.Employee
+	err := s.repository.WithTx(ctx, func(store repository.EmployeeStore) error {
+		var err error
+		if employee, err = current(ctx, store, id, ifMatch); err != nil {
+			return err
+		}
+		before := employee
+		employee.Name = input.Name
+		employee.Position = input.Position
+		employee.Salary = input.Salary
+		employee.UpdatedBy = actor(ctx)
+		if err := store.UpdateEmployee(ctx, &employee); err != nil {
+			return err
+		}
+		return audit(ctx, store, models.AuditUpdate, id, &before, &employee)
+	})
+	if err != nil {
+		recordError(span, err)
+		return models.Employee{}, err
+	}
+	return employee, nil
+}
+
+// PatchEmployee applies p to the employee's JSON form and writes back only
+// the fields it changed, so fields the client left out keep their values.
+// The patched employee must pass the same rules as on create.
+func (s *EmployeeService) PatchEmployee(ctx context.Context, id int, ifMatch IfMatch, p patch.Patch) (models.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.PatchEmployee", trace.WithAttributes(attribute.Int("employee.id", id)))
+	defer span.End()
+
+	var employee models.Employee
+	err := s.repository.WithTx(ctx, func(store repository.EmployeeStore) error {
+		var err error
+		if employee, err = current(ctx, store, id, ifMatch); err != nil {
+			return err
+		}
+		changes, err := employeeChanges(employee, p)
+		if err != nil || changes.IsEmpty() {
+			return err
+		}
+		changes.UpdatedBy = actor(ctx)
+		patched := employee
+		changes.ApplyTo(&patched)
+		if err := s.validator.Struct(EmployeeInput{Name: patched.Name, Position: patched.Position, Salary: patched.Salary}); err != nil {
+			return err
+		}
+		if _, err := store.PatchEmployee(ctx, id, employee.Version, changes); err != nil {
+			return err
+		}
+		// Read back what the store set, such as UpdatedAt
+		before := employee
+		if employee, err = store.GetEmployeeByID(ctx, id, nil); err != nil {
+			return err
+		}
+		return audit(ctx, store, models.AuditUpdate, id, &before, &employee)
+	})
+	if err != nil {
+		recordError(span, err)
+		return models.Employee{}, err
+	}
+	return employee, nil
 }
 
-func (s *EmployeeService) ListEmployees(page, limit int) ([]models.Employee, error) {
+func (s *EmployeeService) DeleteEmployee(ctx context.Context, id int, ifMatch IfMatch) error {
+	ctx, span := tracer.Start(ctx, "EmployeeService.DeleteEmployee", trace.WithAttributes(attribute.Int("employee.id", id)))
+	defer span.End()
+
+	err := s.repository.WithTx(ctx, func(store repository.EmployeeStore) error {
+		employee, err := current(ctx, store, id, ifMatch)
+		if err != nil {
+			return err
+		}
+		if err := store.DeleteEmployee(ctx, id, employee.Version, actor(ctx)); err != nil {
+			return err
+		}
+		return audit(ctx, store, models.AuditDelete, id, &employee, nil)
+	})
+	recordError(span, err)
+	return err
+}
+
+// RestoreEmployee undoes the soft delete of an employee that has not been
+// purged yet.
+func (s *EmployeeService) RestoreEmployee(ctx context.Context, id int, ifMatch IfMatch) (models.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.RestoreEmployee", trace.WithAttributes(attribute.Int("employee.id", id)))
+	defer span.End()
+
+	var employee models.Employee
+	err := s.repository.WithTx(ctx, func(store repository.EmployeeStore) error {
+		var err error
+		if employee, err = current(repository.IncludeDeleted(ctx), store, id, ifMatch); err != nil {
+			return err
+		}
+		if !employee.IsDeleted() {
+			return ErrNotDeleted
+		}
+		if _, err := store.RestoreEmployee(ctx, id, employee.Version, actor(ctx)); err != nil {
+			return err
+		}
+		if employee, err = store.GetEmployeeByID(ctx, id, nil); err != nil {
+			return err
+		}
+		return audit(ctx, store, models.AuditRestore, id, nil, &employee)
+	})
+	if err != nil {
+		recordError(span, err)
+		return models.Employee{}, err
+	}
+	return employee, nil
+}
+
+// actor names the caller making a change, for the CreatedBy, UpdatedBy and
+// DeletedBy columns and the audit log. It is the subject of the caller's
+// token; work without a caller, such as a job, is done by "system".
+func actor(ctx context.Context) string {
+	if subject := auth.SubjectFromContext(ctx); subject != "" {
+		return subject
+	}
+	return "system"
+}
+
+// current reads the employee a write will be based on and checks it is a
+// version the caller allows.
+func current(ctx context.Context, store repository.EmployeeStore, id int, ifMatch IfMatch) (models.Employee, error) {
+	employee, err := store.GetEmployeeByID(ctx, id, nil)
+	if err != nil {
+		return models.Employee{}, err
+	}
+	if !ifMatch.allows(employee.Version) {
+		return models.Employee{}, repository.ErrVersionMismatch
+	}
+	return employee, nil
+}
+
+// EmployeePage is one page of the employee list. Page is 1-based.
+type EmployeePage struct {
+	Employees []models.Employee
+	Page      int
+	Limit     int
+	Total     int64
+}
+
+func (p EmployeePage) TotalPages() int {
+	return int((p.Total + int64(p.Limit) - 1) / int64(p.Limit))
+}
+
+func (p EmployeePage) HasNext() bool {
+	return p.Page < p.TotalPages()
+}
+
+func (s *EmployeeService) ListEmployees(ctx context.Context, query repository.ListQuery, page, limit int) (EmployeePage, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.ListEmployees", trace.WithAttributes(
+		attribute.Int("page", page),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
 	offset := (page - 1) * limit
-	return s.repository.ListEmployee(offset, limit)
+	employees, total, err := s.repository.ListEmployee(ctx, query, offset, limit)
+	recordError(span, err)
+	return EmployeePage{Employees: employees, Page: page, Limit: limit, Total: total}, err
+}
+
+// EmployeeCursorPage is one page of a keyset walk over the employees. When
+// HasNext is set the next page starts after the last employee.
+type EmployeeCursorPage struct {
+	Employees []models.Employee
+	Limit     int
+	HasNext   bool
+}
+
+// ListEmployeesAfter returns the page after the given employee, or the first
+// page when after is nil.
+func (s *EmployeeService) ListEmployeesAfter(ctx context.Context, query repository.ListQuery, after *models.Employee, limit int) (EmployeeCursorPage, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.ListEmployeesAfter", trace.WithAttributes(
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	// One extra row tells whether another page follows without counting
+	employees, err := s.repository.ListEmployeesAfter(ctx, query, after, limit+1)
+	if err != nil {
+		recordError(span, err)
+		return EmployeeCursorPage{}, err
+	}
+	page := EmployeeCursorPage{Employees: employees, Limit: limit}
+	if len(employees) > limit {
+		page.Employees = employees[:limit]
+		page.HasNext = true
+	}
+	return page, nil
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
 }
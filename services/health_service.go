@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheck reports an error when a dependency is unusable.
+type HealthCheck func(ctx context.Context) error
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+)
+
+// CheckResult is the outcome of one check. Error is for the logs only: the
+// health endpoints are unauthenticated and driver errors name hosts and
+// tables.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"-"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthService runs the registered dependency checks for the readiness and
+// health endpoints and tracks whether the server is draining.
+type HealthService struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+func NewHealthService(timeout time.Duration) *HealthService {
+	return &HealthService{timeout: timeout}
+}
+
+func (s *HealthService) Register(name string, check HealthCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks = append(s.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining makes readiness fail from now on, so load balancers stop
+// sending traffic while in-flight requests finish.
+func (s *HealthService) SetDraining() {
+	s.draining.Store(true)
+}
+
+func (s *HealthService) Draining() bool {
+	return s.draining.Load()
+}
+
+// Check runs every registered check concurrently, each bounded by the
+// service timeout.
+func (s *HealthService) Check(ctx context.Context) HealthReport {
+	s.mu.RLock()
+	checks := append([]namedCheck(nil), s.checks...)
+	s.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(checkCtx)
+			results[i] = CheckResult{
+				Name:      c.name,
+				Status:    StatusOK,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Status = StatusUnavailable
+				results[i].Error = err.Error()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := HealthReport{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	if s.Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-assessment/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_Check(t *testing.T) {
+	// Setup
+	service := services.NewHealthService(50 * time.Millisecond)
+	service.Register("database", func(ctx context.Context) error { return nil })
+
+	t.Run("TestCheck_Healthy", func(t *testing.T) {
+		report := service.Check(context.Background())
+		assert.Equal(t, services.StatusOK, report.Status)
+		assert.Len(t, report.Checks, 1)
+		assert.Equal(t, "database", report.Checks[0].Name)
+		assert.Equal(t, services.StatusOK, report.Checks[0].Status)
+	})
+
+	t.Run("TestCheck_FailingDependency", func(t *testing.T) {
+		service.Register("migrations", func(ctx context.Context) error { return errors.New("schema is behind") })
+		report := service.Check(context.Background())
+		assert.Equal(t, services.StatusUnavailable, report.Status)
+		assert.Equal(t, "migrations", report.Checks[1].Name)
+		assert.Equal(t, "schema is behind", report.Checks[1].Error)
+	})
+
+	t.Run("TestCheck_Timeout", func(t *testing.T) {
+		slow := services.NewHealthService(20 * time.Millisecond)
+		slow.Register("database", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		report := slow.Check(context.Background())
+		assert.Equal(t, services.StatusUnavailable, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	})
+
+	t.Run("TestCheck_Draining", func(t *testing.T) {
+		healthy := services.NewHealthService(time.Second)
+		assert.False(t, healthy.Draining())
+		healthy.SetDraining()
+		assert.True(t, healthy.Draining())
+		assert.Equal(t, services.StatusDraining, healthy.Check(context.Background()).Status)
+	})
+}
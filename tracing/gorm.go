@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const parentContextKey = "tracing:parent_context"
+
+// GormPlugin opens a client span around every statement GORM runs. Spans
+// carry the SQL text with placeholders, never the bound values, so no
+// employee data ends up in traces.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", endSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", endSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", endSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", endSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan),
+	)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		parent := db.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, _ := otel.Tracer(instrumentationName).Start(parent, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(db.Dialector.Name()),
+				semconv.DBOperationNameKey.String(operation),
+			),
+		)
+		// The statement may be reused (e.g. inside a transaction), so the
+		// parent is restored once the span ends.
+		db.InstanceSet(parentContextKey, parent)
+		db.Statement.Context = ctx
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	span := trace.SpanFromContext(db.Statement.Context)
+	if !span.IsRecording() {
+		restoreParent(db)
+		return
+	}
+
+	span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()))
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionNameKey.String(db.Statement.Table))
+	}
+	span.SetAttributes(rowsAffectedKey.Int64(db.Statement.RowsAffected))
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+	restoreParent(db)
+}
+
+func restoreParent(db *gorm.DB) {
+	if parent, ok := db.InstanceGet(parentContextKey); ok {
+		db.Statement.Context = parent.(context.Context)
+	}
+}
@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"golang-assessment/models"
+	repository "golang-assessment/respository"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var rowsAffectedKey = attribute.Key("db.rows_affected")
+
+// tracedStore decorates an EmployeeStore with one span per call, so the
+// in-memory backend is traced the same way as the GORM one.
+type tracedStore struct {
+	next repository.EmployeeStore
+}
+
+func TraceStore(store repository.EmployeeStore) repository.EmployeeStore {
+	return &tracedStore{next: store}
+}
+
+func startStoreSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, "EmployeeStore."+name, trace.WithAttributes(attributes...))
+}
+
+func endStoreSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// WithTx spans the whole transaction and traces the calls made in it.
+func (s *tracedStore) WithTx(ctx context.Context, fn func(store repository.EmployeeStore) error) (err error) {
+	ctx, span := startStoreSpan(ctx, "WithTx")
+	defer func() { endStoreSpan(span, err) }()
+	return s.next.WithTx(ctx, func(tx repository.EmployeeStore) error {
+		return fn(TraceStore(tx))
+	})
+}
+
+func (s *tracedStore) CreateEmployee(ctx context.Context, employee *models.Employee) error {
+	ctx, span := startStoreSpan(ctx, "CreateEmployee")
+	err := s.next.CreateEmployee(ctx, employee)
+	span.SetAttributes(attribute.Int("employee.id", employee.ID))
+	endStoreSpan(span, err)
+	return err
+}
+
+func (s *tracedStore) GetEmployeeByID(ctx context.Context, id int, fields repository.Fields) (models.Employee, error) {
+	ctx, span := startStoreSpan(ctx, "GetEmployeeByID", attribute.Int("employee.id", id))
+	employee, err := s.next.GetEmployeeByID(ctx, id, fields)
+	endStoreSpan(span, err)
+	return employee, err
+}
+
+func (s *tracedStore) UpdateEmployee(ctx context.Context, employee *models.Employee) error {
+	ctx, span := startStoreSpan(ctx, "UpdateEmployee", attribute.Int("employee.id", employee.ID), attribute.Int("employee.version", employee.Version))
+	err := s.next.UpdateEmployee(ctx, employee)
+	endStoreSpan(span, err)
+	return err
+}
+
+func (s *tracedStore) PatchEmployee(ctx context.Context, id, version int, changes repository.EmployeeChanges) (int, error) {
+	ctx, span := startStoreSpan(ctx, "PatchEmployee", attribute.Int("employee.id", id), attribute.Int("employee.version", version))
+	newVersion, err := s.next.PatchEmployee(ctx, id, version, changes)
+	endStoreSpan(span, err)
+	return newVersion, err
+}
+
+func (s *tracedStore) DeleteEmployee(ctx context.Context, id, version int, actor string) error {
+	ctx, span := startStoreSpan(ctx, "DeleteEmployee", attribute.Int("employee.id", id), attribute.Int("employee.version", version))
+	err := s.next.DeleteEmployee(ctx, id, version, actor)
+	endStoreSpan(span, err)
+	return err
+}
+
+func (s *tracedStore) RestoreEmployee(ctx context.Context, id, version int, actor string) (int, error) {
+	ctx, span := startStoreSpan(ctx, "RestoreEmployee", attribute.Int("employee.id", id), attribute.Int("employee.version", version))
+	newVersion, err := s.next.RestoreEmployee(ctx, id, version, actor)
+	endStoreSpan(span, err)
+	return newVersion, err
+}
+
+func (s *tracedStore) PurgeEmployees(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, span := startStoreSpan(ctx, "PurgeEmployees", attribute.String("deleted_before", deletedBefore.Format(time.RFC3339)))
+	purged, err := s.next.PurgeEmployees(ctx, deletedBefore)
+	span.SetAttributes(attribute.Int64("employees.purged", purged))
+	endStoreSpan(span, err)
+	return purged, err
+}
+
+// queryAttributes records which filters a list uses and its order. Filter
+// values are left out: name_contains is typed by users and the salary bounds
+// are financial data.
+func queryAttributes(query repository.ListQuery) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.StringSlice("query.filters", query.FilterNames()),
+		attribute.String("query.sort", query.SortString()),
+	}
+}
+
+func (s *tracedStore) ListEmployee(ctx context.Context, query repository.ListQuery, offset, limit int) ([]models.Employee, int64, error) {
+	ctx, span := startStoreSpan(ctx, "ListEmployee", append(queryAttributes(query), attribute.Int("offset", offset), attribute.Int("limit", limit))...)
+	employees, total, err := s.next.ListEmployee(ctx, query, offset, limit)
+	span.SetAttributes(attribute.Int("employees.returned", len(employees)), attribute.Int64("employees.total", total))
+	endStoreSpan(span, err)
+	return employees, total, err
+}
+
+func (s *tracedStore) ListEmployeesAfter(ctx context.Context, query repository.ListQuery, after *models.Employee, limit int) ([]models.Employee, error) {
+	ctx, span := startStoreSpan(ctx, "ListEmployeesAfter", append(queryAttributes(query), attribute.Int("limit", limit))...)
+	employees, err := s.next.ListEmployeesAfter(ctx, query, after, limit)
+	span.SetAttributes(attribute.Int("employees.returned", len(employees)))
+	endStoreSpan(span, err)
+	return employees, err
+}
+
+func (s *tracedStore) CountEmployees(ctx context.Context) (int64, error) {
+	ctx, span := startStoreSpan(ctx, "CountEmployees")
+	count, err := s.next.CountEmployees(ctx)
+	endStoreSpan(span, err)
+	return count, err
+}
+
+func (s *tracedStore) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	ctx, span := startStoreSpan(ctx, "AppendAudit", attribute.Int("employee.id", entry.EmployeeID), attribute.String("audit.operation", entry.Operation))
+	err := s.next.AppendAudit(ctx, entry)
+	endStoreSpan(span, err)
+	return err
+}
+
+func (s *tracedStore) ListAudit(ctx context.Context, query repository.AuditQuery, after int64, limit int) ([]models.AuditEntry, error) {
+	ctx, span := startStoreSpan(ctx, "ListAudit", attribute.String("query", query.String()), attribute.Int("limit", limit))
+	entries, err := s.next.ListAudit(ctx, query, after, limit)
+	span.SetAttributes(attribute.Int("audit.returned", len(entries)))
+	endStoreSpan(span, err)
+	return entries, err
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"golang-assessment/config"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const instrumentationName = "golang-assessment"
+
+// Setup installs the global tracer provider and W3C trace context
+// propagator. The returned function flushes and stops the exporter; it is
+// safe to call when tracing is disabled.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.TracingExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TracingExporterStdout:
+		exporter, err = NewStdoutExporter(os.Stdout)
+	case config.TracingExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := NewTracerProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider builds a provider with the service resource and sampler
+// from cfg. Tests pass a span processor backed by an in-memory recorder.
+func NewTracerProvider(cfg config.TracingConfig, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		// Follow the caller's sampling decision when there is one
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, options...)
+	return sdktrace.NewTracerProvider(options...)
+}
+
+func NewStdoutExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+}
@@ -0,0 +1,173 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-assessment/config"
+	"golang-assessment/controller"
+	loggerNew "golang-assessment/logger"
+	"golang-assessment/migrations"
+	"golang-assessment/models"
+	"golang-assessment/pagination"
+	"golang-assessment/redact"
+	repository "golang-assessment/respository"
+	"golang-assessment/services"
+	"golang-assessment/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := NewTracerProvider(config.DefaultConfig().Tracing, sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func setupTestStore(t *testing.T) repository.EmployeeStore {
+	db, err := config.OpenDatabase(&config.DatabaseConfig{Dialect: config.DialectSQLite, Path: config.SQLiteInMemory})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	migrator, err := migrations.NewMigrator(db, config.DialectSQLite, loggerNew.Discard())
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("error migrating database: %v", err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatalf("error installing plugin: %v", err)
+	}
+	store := repository.NewEmployeeRepository(db, loggerNew.Discard())
+	store.CreateEmployee(context.Background(), &models.Employee{Name: "John Doe", Position: "Developer", Salary: 60000})
+	return TraceStore(store)
+}
+
+func spanNamed(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestTracing_RequestAcrossLayers(t *testing.T) {
+	// Setup
+	store := setupTestStore(t)
+	recorder := setupTestTracing(t)
+	employeeController := controller.NewEmployeeController(services.NewEmployeeService(store, validation.New(config.DefaultConfig().Validation)), redact.NewPolicy(config.DefaultConfig().Redaction), pagination.NewLimits(config.DefaultConfig().Pagination), config.ConcurrencyConfig{}, config.DefaultConfig().SoftDelete, pagination.NewCursorCodec([]byte("test")), loggerNew.Discard())
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/employees/:id", employeeController.GetEmployeeByID)
+
+	req, _ := http.NewRequest("GET", "/employees/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	spans := recorder.Ended()
+	server := spanNamed(spans, "GET /employees/:id")
+	service := spanNamed(spans, "EmployeeService.GetEmployeeByID")
+	storeSpan := spanNamed(spans, "EmployeeStore.GetEmployeeByID")
+	query := spanNamed(spans, "gorm.query")
+	if !assert.NotNil(t, server) || !assert.NotNil(t, service) || !assert.NotNil(t, storeSpan) || !assert.NotNil(t, query) {
+		return
+	}
+
+	// The caller's trace is continued and every layer nests under it
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+	assert.Equal(t, service.SpanContext().SpanID(), storeSpan.Parent().SpanID())
+	assert.Equal(t, storeSpan.SpanContext().SpanID(), query.Parent().SpanID())
+
+	// Queries are recorded with placeholders, not values
+	for _, attr := range query.Attributes() {
+		if attr.Key == "db.query.text" {
+			assert.Contains(t, attr.Value.AsString(), "SELECT")
+			assert.NotContains(t, attr.Value.AsString(), "John Doe")
+		}
+	}
+}
+
+func TestTracing_ErrorStatus(t *testing.T) {
+	store := setupTestStore(t)
+	recorder := setupTestTracing(t)
+
+	_, err := store.GetEmployeeByID(context.Background(), 100, nil)
+	assert.NotNil(t, err)
+
+	span := spanNamed(recorder.Ended(), "EmployeeStore.GetEmployeeByID")
+	if assert.NotNil(t, span) {
+		assert.Equal(t, "Error", span.Status().Code.String())
+	}
+	// A missing row is not a database failure
+	query := spanNamed(recorder.Ended(), "gorm.query")
+	if assert.NotNil(t, query) {
+		assert.Equal(t, "Unset", query.Status().Code.String())
+	}
+}
+
+func TestTracing_ListQueryValuesLeftOut(t *testing.T) {
+	store := setupTestStore(t)
+	recorder := setupTestTracing(t)
+	salary := 55000.0
+
+	query := repository.ListQuery{
+		Filter: repository.EmployeeFilter{NameContains: "doe", SalaryGTE: &salary},
+		Sort:   []repository.SortKey{{Field: "salary", Desc: true}},
+	}
+	_, err := store.ListEmployeesAfter(context.Background(), query, nil, 10)
+	assert.Nil(t, err)
+
+	span := spanNamed(recorder.Ended(), "EmployeeStore.ListEmployeesAfter")
+	if !assert.NotNil(t, span) {
+		return
+	}
+	attributes := map[string]string{}
+	for _, attr := range span.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, `["name_contains","salary_gte"]`, attributes["query.filters"])
+	assert.Equal(t, "-salary,id", attributes["query.sort"])
+	for _, value := range attributes {
+		assert.NotContains(t, value, "doe")
+		assert.NotContains(t, value, "55000")
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter, err := NewStdoutExporter(&out)
+	assert.Nil(t, err)
+	provider := NewTracerProvider(config.DefaultConfig().Tracing, sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "offline-span")
+	span.End()
+	assert.Nil(t, provider.Shutdown(context.Background()))
+	assert.Contains(t, out.String(), "offline-span")
+}
+
+func TestSetup_None(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: config.TracingExporterNone})
+	assert.Nil(t, err)
+	assert.Nil(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,127 @@
+// Package validation checks input structs against the rules declared in
+// their `validate` tags and reports every failing field at once.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"golang-assessment/apperrors"
+	"golang-assessment/config"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+)
+
+// FieldError is one rule a field broke. Field is the field's JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error lists every field of an input that broke a rule. It is an
+// apperrors.ErrValidation.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		problems[i] = field.Field + " " + field.Message
+	}
+	return "validation failed: " + strings.Join(problems, "; ")
+}
+
+func (e *Error) Unwrap() error {
+	return apperrors.ErrValidation
+}
+
+// Validator checks structs against their validate tags. On top of the
+// validator package's tags it knows:
+//
+//	notblank  the string has a non-space character
+//	salary    the number is below the configured maximum salary
+//	position  the string is in the configured position catalog, if any
+type Validator struct {
+	validate  *validator.Validate
+	maxSalary float64
+	positions []string
+}
+
+func New(cfg config.ValidationConfig) *Validator {
+	v := &Validator{
+		validate:  validator.New(validator.WithRequiredStructEnabled()),
+		maxSalary: cfg.MaxSalary,
+		positions: cfg.Positions,
+	}
+	v.validate.RegisterTagNameFunc(jsonName)
+	// The tags are constants, so registering them cannot fail
+	_ = v.validate.RegisterValidation("notblank", validators.NotBlank)
+	_ = v.validate.RegisterValidation("salary", func(fl validator.FieldLevel) bool {
+		return fl.Field().Float() < v.maxSalary
+	})
+	_ = v.validate.RegisterValidation("position", func(fl validator.FieldLevel) bool {
+		return len(v.positions) == 0 || v.allowedPosition(fl.Field().String())
+	})
+	return v
+}
+
+func (v *Validator) allowedPosition(position string) bool {
+	for _, allowed := range v.positions {
+		if position == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Struct returns an *Error listing every rule s breaks, or nil.
+func (v *Validator) Struct(s interface{}) error {
+	err := v.validate.Struct(s)
+	var failed validator.ValidationErrors
+	if !errors.As(err, &failed) {
+		// nil, or s is not a struct, which is a bug in the caller
+		return err
+	}
+	fields := make([]FieldError, len(failed))
+	for i, fieldErr := range failed {
+		fields[i] = FieldError{Field: fieldErr.Field(), Message: v.message(fieldErr)}
+	}
+	return &Error{Fields: fields}
+}
+
+func (v *Validator) message(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "isdefault":
+		return "is assigned by the server and cannot be set"
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fieldErr.Param())
+		}
+		return "must be at most " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "salary":
+		return fmt.Sprintf("must be below %v", v.maxSalary)
+	case "position":
+		return "must be one of " + strings.Join(v.positions, ", ")
+	default:
+		return fmt.Sprintf("breaks the %s rule", fieldErr.Tag())
+	}
+}
+
+// jsonName names fields as clients know them.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,52 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"golang-assessment/apperrors"
+	"golang-assessment/config"
+	"golang-assessment/services"
+	"golang-assessment/validation"
+)
+
+func TestValidator_Struct(t *testing.T) {
+	// Setup
+	validator := validation.New(config.ValidationConfig{MaxSalary: 100000, Positions: []string{"Developer", "Manager"}})
+
+	t.Run("TestStruct_Valid", func(t *testing.T) {
+		assert.Nil(t, validator.Struct(services.EmployeeInput{Name: "John Doe", Position: "Developer", Salary: 99999.99}))
+	})
+
+	t.Run("TestStruct_EveryFieldReported", func(t *testing.T) {
+		id := 0
+		err := validator.Struct(services.EmployeeInput{ID: &id, Position: "Astronaut", Salary: 100000})
+
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
+		var invalid *validation.Error
+		assert.ErrorAs(t, err, &invalid)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "id", Message: "is assigned by the server and cannot be set"},
+			{Field: "name", Message: "is required"},
+			{Field: "position", Message: "must be one of Developer, Manager"},
+			{Field: "salary", Message: "must be below 100000"},
+		}, invalid.Fields)
+		assert.EqualError(t, err, "validation failed: id is assigned by the server and cannot be set; name is required; position must be one of Developer, Manager; salary must be below 100000")
+	})
+
+	t.Run("TestStruct_LengthInCharacters", func(t *testing.T) {
+		// 100 characters, but 200 bytes
+		name := ""
+		for i := 0; i < 100; i++ {
+			name += "é"
+		}
+		assert.Nil(t, validator.Struct(services.EmployeeInput{Name: name, Position: "Manager", Salary: 1}))
+		assert.NotNil(t, validator.Struct(services.EmployeeInput{Name: name + "é", Position: "Manager", Salary: 1}))
+	})
+
+	t.Run("TestStruct_EmptyCatalogAllowsAnyPosition", func(t *testing.T) {
+		open := validation.New(config.ValidationConfig{MaxSalary: 100000})
+		assert.Nil(t, open.Struct(services.EmployeeInput{Name: "John Doe", Position: "Astronaut", Salary: 1}))
+	})
+}